@@ -30,6 +30,16 @@ const (
 					}
 				]
 			}`
+	IAM_EVENTS_ASSUME_ROLE IAMRoleArgs = `{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Action": "sts:AssumeRole",
+				"Principal": {
+					"Service": "events.amazonaws.com"
+				},
+				"Effect": "Allow"
+			}]
+		}`
 	IAM_APIGW_ASSUME_ROLE IAMRoleArgs = `{
 			"Version": "2012-10-17",
 			"Statement": [
@@ -38,7 +48,7 @@ const (
 					"Principal": {
 						"Service": "apigateway.amazonaws.com"
 					},
-					"Effect": "Allow",
+					"Effect": "Allow"
 				}
 			]
 		}`
@@ -58,15 +68,39 @@ const (
 
 	// Queues
 	SQS_MANAGE PolicyGroup = "SQS_MANAGE"
+	SQS_SEND   PolicyGroup = "SQS_SEND"
+
+	// Streams
+	DYNAMODB_STREAM_READ PolicyGroup = "DYNAMODB_STREAM_READ"
+	KINESIS_STREAM_READ  PolicyGroup = "KINESIS_STREAM_READ"
+	// ListStreams accetta solo Resource "*"
+	DYNAMODB_LIST_STREAMS PolicyGroup = "DYNAMODB_LIST_STREAMS"
+	KINESIS_LIST_STREAMS  PolicyGroup = "KINESIS_LIST_STREAMS"
+
+	// Events
+	EVENTBRIDGE_PUT_EVENTS PolicyGroup = "EVENTBRIDGE_PUT_EVENTS"
 
 	// DB
 	DYNAMODB_RW PolicyGroup = "DYNAMODB_RW"
 
 	// Compute
 	LAMBDA_INVOKE PolicyGroup = "LAMBDA_INVOKE"
+	ECS_EXEC      PolicyGroup = "ECS_EXEC"
+	ECS_RUN_TASK  PolicyGroup = "ECS_RUN_TASK"
+	// Tag sulle risorse create da RunTask (condizione ecs:CreateAction)
+	ECS_TAG_RESOURCE PolicyGroup = "ECS_TAG_RESOURCE"
+
+	// IAM
+	IAM_PASS_ROLE PolicyGroup = "IAM_PASS_ROLE"
 
 	// Notifications / Email
 	SNS_SEND_EMAIL PolicyGroup = "SNS_SEND_EMAIL"
+	SNS_PUBLISH    PolicyGroup = "SNS_PUBLISH"
+
+	// Secrets
+	SECRETS_MANAGER_READ PolicyGroup = "SECRETS_MANAGER_READ"
+	SSM_PARAMETERS_READ  PolicyGroup = "SSM_PARAMETERS_READ"
+	KMS_DECRYPT          PolicyGroup = "KMS_DECRYPT"
 )
 
 type PolicySet struct {
@@ -103,6 +137,31 @@ func DefaultPolicySet() *PolicySet {
 				"sqs:GetQueueUrl",
 				"sqs:ChangeMessageVisibility",
 			},
+			DYNAMODB_STREAM_READ: {
+				"dynamodb:DescribeStream",
+				"dynamodb:GetRecords",
+				"dynamodb:GetShardIterator",
+			},
+			DYNAMODB_LIST_STREAMS: {
+				"dynamodb:ListStreams",
+			},
+			KINESIS_STREAM_READ: {
+				"kinesis:DescribeStream",
+				"kinesis:DescribeStreamSummary",
+				"kinesis:GetRecords",
+				"kinesis:GetShardIterator",
+				"kinesis:ListShards",
+				"kinesis:SubscribeToShard",
+			},
+			KINESIS_LIST_STREAMS: {
+				"kinesis:ListStreams",
+			},
+			SQS_SEND: {
+				"sqs:SendMessage",
+			},
+			EVENTBRIDGE_PUT_EVENTS: {
+				"events:PutEvents",
+			},
 			DYNAMODB_RW: {
 				"dynamodb:PutItem",
 				"dynamodb:GetItem",
@@ -114,11 +173,38 @@ func DefaultPolicySet() *PolicySet {
 			LAMBDA_INVOKE: {
 				"lambda:InvokeFunction",
 			},
+			ECS_RUN_TASK: {
+				"ecs:RunTask",
+			},
+			ECS_TAG_RESOURCE: {
+				"ecs:TagResource",
+			},
+			IAM_PASS_ROLE: {
+				"iam:PassRole",
+			},
+			ECS_EXEC: {
+				"ssmmessages:CreateControlChannel",
+				"ssmmessages:CreateDataChannel",
+				"ssmmessages:OpenControlChannel",
+				"ssmmessages:OpenDataChannel",
+			},
 			SNS_SEND_EMAIL: {
 				"sns:Publish",
 				"ses:SendEmail",
 				"ses:SendRawEmail",
 			},
+			SNS_PUBLISH: {
+				"sns:Publish",
+			},
+			SECRETS_MANAGER_READ: {
+				"secretsmanager:GetSecretValue",
+			},
+			SSM_PARAMETERS_READ: {
+				"ssm:GetParameters",
+			},
+			KMS_DECRYPT: {
+				"kms:Decrypt",
+			},
 		},
 	}
 }
@@ -167,6 +253,9 @@ func toPulumiStrings(ss []string) pulumi.StringArray {
 type StatementSpec struct {
 	Groups    []PolicyGroup
 	Resources []string // e.g. []string{"*"} or specific ARNs
+
+	// ARNs known only at deploy time (Pulumi outputs), appended to Resources
+	ResourceArns pulumi.StringArray
 }
 
 // Helpers for callers:
@@ -183,7 +272,26 @@ func (ps *PolicySet) Statement(resources []string, groups ...PolicyGroup) iam.Ge
 func (ps *PolicySet) Build(specs ...StatementSpec) iam.GetPolicyDocumentStatementArray {
 	out := make(iam.GetPolicyDocumentStatementArray, 0, len(specs))
 	for _, s := range specs {
-		out = append(out, ps.Statement(s.Resources, s.Groups...))
+		st := ps.Statement(s.Resources, s.Groups...)
+		if len(s.ResourceArns) > 0 {
+			st.Resources = append(toPulumiStrings(s.Resources), s.ResourceArns...)
+		}
+		out = append(out, st)
 	}
 	return out
 }
+
+// ArnStatement grants the actions of the group picked from the ARN at deploy time,
+// for resources known only as Pulumi outputs (es. a queue or a topic)
+func (ps *PolicySet) ArnStatement(arn pulumi.StringInput, groupOf func(arn string) (PolicyGroup, error)) iam.GetPolicyDocumentStatementArgs {
+	return iam.GetPolicyDocumentStatementArgs{
+		Actions: arn.ToStringOutput().ApplyT(func(a string) ([]string, error) {
+			group, err := groupOf(a)
+			if err != nil {
+				return nil, err
+			}
+			return ps.actionsOf(group), nil
+		}).(pulumi.StringArrayOutput),
+		Resources: pulumi.StringArray{arn},
+	}
+}
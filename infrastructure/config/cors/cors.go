@@ -0,0 +1,182 @@
+package cors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ALLOW_ORIGIN      = "Access-Control-Allow-Origin"
+	ALLOW_METHODS     = "Access-Control-Allow-Methods"
+	ALLOW_HEADERS     = "Access-Control-Allow-Headers"
+	ALLOW_CREDENTIALS = "Access-Control-Allow-Credentials"
+	EXPOSE_HEADERS    = "Access-Control-Expose-Headers"
+	MAX_AGE           = "Access-Control-Max-Age"
+)
+
+// ENV_VAR is the Lambda environment variable carrying the encoded policy (see Encode)
+const ENV_VAR = "CORS_POLICY"
+
+// Config is the CORS policy shared by the API Gateway preflight and the Lambda responses
+type Config struct {
+	AllowOrigins     []string // "*" for any origin
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int // seconds, 0 to omit
+}
+
+// FUNCTION_URL_MAX_AGE is the highest MaxAge accepted by the Lambda Function URLs
+const FUNCTION_URL_MAX_AGE = 86400
+
+// functionUrlMethods are the methods accepted by the Lambda Function URL CORS
+var functionUrlMethods = []string{"GET", "PUT", "HEAD", "POST", "PATCH", "DELETE", "*"}
+
+// DEFAULT is the policy applied when none is configured
+var DEFAULT = Config{
+	AllowOrigins: []string{"*"},
+	AllowMethods: []string{"POST", "OPTIONS", "GET", "PUT", "PATCH", "DELETE"},
+	AllowHeaders: []string{"Content-Type", "X-Amz-Date", "Authorization", "X-Api-Key", "X-Amz-Security-Token"},
+}
+
+// Encode serializes the policy for ENV_VAR
+func (c Config) Encode() (string, error) {
+	out, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Decode parses a policy serialized by Encode
+func Decode(encoded string) (Config, error) {
+	var c Config
+	if err := json.Unmarshal([]byte(encoded), &c); err != nil {
+		return Config{}, fmt.Errorf("cors: invalid policy: %w", err)
+	}
+	return c, c.Validate()
+}
+
+// Validate checks the combinations rejected by the browsers
+func (c Config) Validate() error {
+	if len(c.AllowOrigins) == 0 {
+		return errors.New("cors: AllowOrigins is required")
+	}
+	if c.AllowCredentials && c.anyOrigin() {
+		return errors.New("cors: AllowCredentials cannot be used with the \"*\" origin")
+	}
+	return nil
+}
+
+// FunctionUrl returns the policy accepted by the Lambda Function URL CORS: OPTIONS is dropped,
+// since the URL answers the preflight by itself, and MaxAge must not exceed FUNCTION_URL_MAX_AGE
+func (c Config) FunctionUrl() (Config, error) {
+	if c.MaxAge > FUNCTION_URL_MAX_AGE {
+		return Config{}, fmt.Errorf("cors: function url MaxAge %d exceeds %d", c.MaxAge, FUNCTION_URL_MAX_AGE)
+	}
+
+	out := c
+	out.AllowMethods = make([]string, 0, len(c.AllowMethods))
+	for _, m := range c.AllowMethods {
+		m = strings.ToUpper(m)
+		switch {
+		case m == "OPTIONS":
+			continue
+		case !slices.Contains(functionUrlMethods, m):
+			return Config{}, fmt.Errorf("cors: function url does not support the %s method", m)
+		case !slices.Contains(out.AllowMethods, m):
+			out.AllowMethods = append(out.AllowMethods, m)
+		}
+	}
+	return out, nil
+}
+
+// Headers returns the CORS headers of a response to the given request origin.
+// Access-Control-Allow-Origin is omitted when the origin is not allowed
+func (c Config) Headers(origin string) map[string]string {
+	headers := c.staticHeaders()
+	if allowed := c.AllowedOrigin(origin); allowed != "" {
+		headers[ALLOW_ORIGIN] = allowed
+	}
+	if !c.anyOrigin() && len(c.AllowOrigins) > 1 {
+		headers["Vary"] = "Origin"
+	}
+	return headers
+}
+
+// AllowedOrigin returns the Access-Control-Allow-Origin value for the request origin, "" if not allowed.
+// With a single origin it is always returned, so clients without the Origin header still get it
+func (c Config) AllowedOrigin(origin string) string {
+	switch {
+	case c.anyOrigin():
+		return "*"
+	case len(c.AllowOrigins) == 1:
+		return c.AllowOrigins[0]
+	case slices.Contains(c.AllowOrigins, origin):
+		return origin
+	default:
+		return ""
+	}
+}
+
+// PreflightParameters returns the static header values of the MOCK OPTIONS integration,
+// quoted as API Gateway expects ("method.response.header.<name>" -> "'value'")
+func (c Config) PreflightParameters() map[string]string {
+	headers := c.staticHeaders()
+	// Con più origini il valore statico è la prima: il template la sostituisce con quella della richiesta
+	if len(c.AllowOrigins) != 0 {
+		headers[ALLOW_ORIGIN] = c.AllowedOrigin(c.AllowOrigins[0])
+	}
+
+	out := make(map[string]string, len(headers))
+	for name, value := range headers {
+		out["method.response.header."+name] = "'" + value + "'"
+	}
+	return out
+}
+
+// PreflightTemplate returns the MOCK integration response template echoing the request
+// origin when it is allowed, "" when the static value is enough
+func (c Config) PreflightTemplate() string {
+	if c.anyOrigin() || len(c.AllowOrigins) < 2 {
+		return ""
+	}
+
+	conditions := make([]string, 0, len(c.AllowOrigins))
+	for _, o := range c.AllowOrigins {
+		conditions = append(conditions, `$origin == "`+o+`"`)
+	}
+	return strings.Join([]string{
+		`#set($origin = $input.params().header.get("Origin"))`,
+		`#if("$!origin" == "")#set($origin = $input.params().header.get("origin"))#end`,
+		`#if(` + strings.Join(conditions, " || ") + `)`,
+		`#set($context.responseOverride.header.Access-Control-Allow-Origin = $origin)`,
+		`#end`,
+	}, "\n")
+}
+
+func (c Config) staticHeaders() map[string]string {
+	headers := map[string]string{
+		ALLOW_METHODS: strings.Join(c.AllowMethods, ","),
+		ALLOW_HEADERS: strings.Join(c.AllowHeaders, ","),
+	}
+	if len(c.ExposeHeaders) != 0 {
+		headers[EXPOSE_HEADERS] = strings.Join(c.ExposeHeaders, ",")
+	}
+	if c.AllowCredentials {
+		headers[ALLOW_CREDENTIALS] = "true"
+	}
+	if c.MaxAge > 0 {
+		headers[MAX_AGE] = strconv.Itoa(c.MaxAge)
+	}
+	return headers
+}
+
+func (c Config) anyOrigin() bool {
+	return slices.Contains(c.AllowOrigins, "*")
+}
@@ -0,0 +1,153 @@
+package cors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	multi := Config{
+		AllowOrigins:     []string{"https://app.example.com", "https://admin.example.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		origin string
+		want   map[string]string
+	}{
+		{
+			name:   "default",
+			config: DEFAULT,
+			origin: "https://any.example.com",
+			want: map[string]string{
+				ALLOW_ORIGIN:  "*",
+				ALLOW_METHODS: "POST,OPTIONS,GET,PUT,PATCH,DELETE",
+				ALLOW_HEADERS: "Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token",
+			},
+		},
+		{
+			name:   "allowed origin",
+			config: multi,
+			origin: "https://admin.example.com",
+			want: map[string]string{
+				ALLOW_ORIGIN:      "https://admin.example.com",
+				ALLOW_METHODS:     "GET,POST",
+				ALLOW_HEADERS:     "Content-Type",
+				ALLOW_CREDENTIALS: "true",
+				MAX_AGE:           "600",
+				"Vary":            "Origin",
+			},
+		},
+		{
+			name:   "denied origin",
+			config: multi,
+			origin: "https://evil.example.com",
+			want: map[string]string{
+				ALLOW_METHODS:     "GET,POST",
+				ALLOW_HEADERS:     "Content-Type",
+				ALLOW_CREDENTIALS: "true",
+				MAX_AGE:           "600",
+				"Vary":            "Origin",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Headers(tt.origin); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Headers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	params := DEFAULT.PreflightParameters()
+	if got := params["method.response.header."+ALLOW_ORIGIN]; got != "'*'" {
+		t.Errorf("Allow-Origin = %s", got)
+	}
+	if DEFAULT.PreflightTemplate() != "" {
+		t.Error("wildcard origin needs no template")
+	}
+
+	multi := Config{AllowOrigins: []string{"https://a.example.com", "https://b.example.com"}}
+	if got := multi.PreflightParameters()["method.response.header."+ALLOW_ORIGIN]; got != "'https://a.example.com'" {
+		t.Errorf("Allow-Origin = %s", got)
+	}
+	if tpl := multi.PreflightTemplate(); !strings.Contains(tpl, `$origin == "https://b.example.com"`) {
+		t.Errorf("PreflightTemplate() = %s", tpl)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DEFAULT.Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (Config{AllowOrigins: []string{"*"}, AllowCredentials: true}).Validate(); err == nil {
+		t.Error("credentials with wildcard origin should fail")
+	}
+	if err := (Config{}).Validate(); err == nil {
+		t.Error("missing origins should fail")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	policy := Config{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{"GET"},
+		AllowCredentials: true,
+		MaxAge:           60,
+	}
+	encoded, err := policy.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, policy) {
+		t.Errorf("Decode() = %+v, want %+v", got, policy)
+	}
+
+	if _, err := Decode(`{"AllowOrigins": ["*"], "AllowCredentials": true}`); err == nil {
+		t.Error("invalid policy should fail")
+	}
+}
+
+func TestFunctionUrl(t *testing.T) {
+	got, err := DEFAULT.FunctionUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"POST", "GET", "PUT", "PATCH", "DELETE"}; !reflect.DeepEqual(got.AllowMethods, want) {
+		t.Errorf("AllowMethods = %v, want %v", got.AllowMethods, want)
+	}
+	if !reflect.DeepEqual(got.AllowOrigins, DEFAULT.AllowOrigins) || !reflect.DeepEqual(got.AllowHeaders, DEFAULT.AllowHeaders) {
+		t.Errorf("FunctionUrl() should keep origins and headers: %+v", got)
+	}
+	// DEFAULT non viene modificato
+	if len(DEFAULT.AllowMethods) != 6 {
+		t.Errorf("DEFAULT.AllowMethods changed: %v", DEFAULT.AllowMethods)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "max age", config: Config{AllowOrigins: []string{"*"}, MaxAge: FUNCTION_URL_MAX_AGE + 1}},
+		{name: "unsupported method", config: Config{AllowOrigins: []string{"*"}, AllowMethods: []string{"CONNECT"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.FunctionUrl(); err == nil {
+				t.Error("FunctionUrl() should fail")
+			}
+		})
+	}
+}
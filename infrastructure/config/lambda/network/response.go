@@ -3,21 +3,68 @@ package network
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/apperrors"
 	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/axnet"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
 	"github.com/goccy/go-json"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
+// DEFAULT_HEADERS are the CORS headers of the Lambdas without a policy.
+// With ServiceLambdaArgs.Cors (the same cors.Config of the API) the headers follow that policy instead
 var DEFAULT_HEADERS = map[string]string{
 	"Access-Control-Allow-Origin":  "*",
 	"Access-Control-Allow-Methods": "OPTIONS,POST",
 	"Access-Control-Allow-Headers": "*",
 }
 
+// corsPolicy is the policy set by the infrastructure in the cors.ENV_VAR variable, nil if missing
+var corsPolicy = sync.OnceValue(func() *cors.Config {
+	encoded, ok := os.LookupEnv(cors.ENV_VAR)
+	if !ok {
+		return nil
+	}
+	policy, err := cors.Decode(encoded)
+	if err != nil {
+		slog.Error("Invalid CORS policy, using the default headers", "err", err)
+		return nil
+	}
+	return &policy
+})
+
+// corsHeaders returns the CORS headers of the response to the request origin (see WithRequestOrigin)
+func corsHeaders(ctx context.Context) map[string]string {
+	if policy := corsPolicy(); policy != nil {
+		return policy.Headers(RequestOrigin(ctx))
+	}
+	return DEFAULT_HEADERS
+}
+
+type originKey struct{}
+
+// WithRequestOrigin stores the Origin of the request, used to pick the allowed origin of the response
+func WithRequestOrigin(ctx context.Context, req events.APIGatewayProxyRequest) context.Context {
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "Origin") {
+			return context.WithValue(ctx, originKey{}, value)
+		}
+	}
+	return ctx
+}
+
+// RequestOrigin returns the origin stored by WithRequestOrigin
+func RequestOrigin(ctx context.Context) string {
+	origin, _ := ctx.Value(originKey{}).(string)
+	return origin
+}
+
 // APIGatewayRes builds a response compliant to the AWS APIGateway
 func APIGatewayRes[T any](res T, err error) events.APIGatewayProxyResponse {
 	return APIGatewayResCtx(context.Background(), res, err)
@@ -25,7 +72,7 @@ func APIGatewayRes[T any](res T, err error) events.APIGatewayProxyResponse {
 
 // APIGatewayResCtx builds a response compliant to the AWS APIGateway
 func APIGatewayResCtx[T any](ctx context.Context, res T, err error) events.APIGatewayProxyResponse {
-	return APIGatewayStatusResCtx(context.Background(), res, err, nil)
+	return APIGatewayStatusResCtx(ctx, res, err, nil)
 }
 
 // APIGatewayStatusResCtx builds a response compliant to the AWS APIGateway
@@ -35,45 +82,36 @@ func APIGatewayStatusResCtx[T any](
 	err error,
 	statusOpt *int,
 ) events.APIGatewayProxyResponse {
+	status, body := responseEnvelope(ctx, res, err, statusOpt)
+
+	return events.APIGatewayProxyResponse{
+		Headers:    corsHeaders(ctx),
+		StatusCode: status,
+		Body:       body,
+	}
+}
+
+// responseEnvelope marshals the result or the error in the `axnet.Response` envelope
+func responseEnvelope[T any](ctx context.Context, res T, err error, statusOpt *int) (int, string) {
 	if err != nil {
 		body, _ := json.MarshalContext(ctx, axnet.GenerateErrorResponse(err))
 
 		var apperr apperrors.AppError
-		switch errors.As(err, &apperr) {
-		case true:
-			return events.APIGatewayProxyResponse{
-				Headers:    DEFAULT_HEADERS,
-				StatusCode: apperr.StatusCode(),
-				Body:       string(body),
-			}
-		case false:
-			return events.APIGatewayProxyResponse{
-				Headers:    DEFAULT_HEADERS,
-				StatusCode: http.StatusInternalServerError,
-				Body:       string(body),
-			}
+		if errors.As(err, &apperr) {
+			return apperr.StatusCode(), string(body)
 		}
+		return http.StatusInternalServerError, string(body)
 	}
 
 	body, err := json.MarshalContext(ctx, axnet.GenerateResponse(res))
 	if err != nil {
 		body, _ := json.MarshalContext(ctx, axnet.GenerateErrorResponse(err))
-
-		return events.APIGatewayProxyResponse{
-			Headers:    DEFAULT_HEADERS,
-			StatusCode: http.StatusInternalServerError,
-			Body:       string(body),
-		}
+		return http.StatusInternalServerError, string(body)
 	}
 
 	status := http.StatusOK
 	if statusOpt != nil {
 		status = *statusOpt
 	}
-
-	return events.APIGatewayProxyResponse{
-		Headers:    DEFAULT_HEADERS,
-		StatusCode: status,
-		Body:       string(body),
-	}
+	return status, string(body)
 }
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CORS is handled by the Function URL configuration, so only the content type is set
+var FUNCTION_URL_HEADERS = map[string]string{
+	"Content-Type": "application/json",
+}
+
+// FunctionURLRes builds a response compliant to the Lambda Function URLs
+func FunctionURLRes[T any](res T, err error) events.LambdaFunctionURLResponse {
+	return FunctionURLStatusResCtx(context.Background(), res, err, nil)
+}
+
+// FunctionURLResCtx builds a response compliant to the Lambda Function URLs
+func FunctionURLResCtx[T any](ctx context.Context, res T, err error) events.LambdaFunctionURLResponse {
+	return FunctionURLStatusResCtx(ctx, res, err, nil)
+}
+
+// FunctionURLStatusResCtx builds a response compliant to the Lambda Function URLs
+func FunctionURLStatusResCtx[T any](
+	ctx context.Context,
+	res T,
+	err error,
+	statusOpt *int,
+) events.LambdaFunctionURLResponse {
+	status, body := responseEnvelope(ctx, res, err, statusOpt)
+
+	return events.LambdaFunctionURLResponse{
+		Headers:    FUNCTION_URL_HEADERS,
+		StatusCode: status,
+		Body:       body,
+	}
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/apigateway"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -25,10 +26,39 @@ type Methods struct {
 	HttpMethod               string
 	TargetLambdaInvokeArn    pulumi.StringInput
 	TargetLambdaFunctionName pulumi.Input
+	// Alias della Lambda (ServiceLambdaOutput.Qualifier), con TargetLambdaInvokeArn dell'alias
+	TargetLambdaQualifier pulumi.StringPtrInput
+	// Parametri della richiesta, nome -> obbligatorio. I path parameter sono dichiarati dal Path
+	QueryParameters  map[string]bool
+	HeaderParameters map[string]bool
+	// Richiede l'header x-api-key di un usage plan
+	ApiKeyRequired bool
+	// Authorizer per nome (CreateRestAPIInput.Authorizers); vuoto: AuthID dell'input, NO_AUTHORIZER per nessuno
+	Authorizer string
+	// Scope OAuth richiesti, solo con authorizer COGNITO_USER_POOLS
+	AuthorizationScopes []string
+}
+
+// NO_AUTHORIZER disables the authorization of a method
+const NO_AUTHORIZER = "NONE"
+
+type AuthorizerType string
+
+const (
+	AuthorizerTypeToken   AuthorizerType = "TOKEN"
+	AuthorizerTypeRequest AuthorizerType = "REQUEST"
+	AuthorizerTypeCognito AuthorizerType = "COGNITO_USER_POOLS"
+)
+
+// ApiAuthorizer is a created authorizer, referenced by Methods.Authorizer
+type ApiAuthorizer struct {
+	Authorizer *apigateway.Authorizer
+	Type       AuthorizerType
 }
 
 type Endpoints struct {
-	Name    string
+	Name string
+	// Anche annidato, con parametri: "users", "/users/{id}/orders", "/files/{proxy+}"
 	Path    string
 	Methods []Methods
 }
@@ -42,6 +72,9 @@ type CreateRestAPI struct {
 type CreateRestAPIInput struct {
 	CreateRestAPI
 
+	// Alternativo a Endpoints: API definita da un documento OpenAPI 3
+	OpenAPI *OpenAPIInput
+
 	Endpoints          []Endpoints
 	LambdaAuth         *lambda.Function
 	DomainName         string
@@ -49,6 +82,17 @@ type CreateRestAPIInput struct {
 	IdentitySource     string
 	StageName          string
 	Tags               pulumi.StringMapInput
+	// Preflight OPTIONS, default cors.DEFAULT. Passare la stessa policy a ServiceLambdaArgs.Cors
+	// delle Lambda integrate, così le risposte hanno gli stessi header del preflight
+	Cors *cors.Config
+
+	// Authorizer referenziati per nome da Methods.Authorizer (non con OpenAPI: usa LambdaAuth)
+	Authorizers []CreateAuthorizerInput
+
+	// Throttling di default dello stage e override per metodo
+	Throttle       *ApiThrottle
+	MethodSettings []ApiMethodSetting
+	UsagePlans     []UsagePlanArgs
 }
 
 type CreateEndpointsInput struct {
@@ -58,6 +102,9 @@ type CreateEndpointsInput struct {
 	RestApi    *apigateway.RestApi
 	AllOptions []pulumi.Resource
 	AuthID     *pulumi.StringPtrInput
+	Cors       *cors.Config
+	// Per nome, vedi Methods.Authorizer
+	Authorizers map[string]ApiAuthorizer
 }
 
 type CreateMethodIntegrationInput struct {
@@ -67,23 +114,44 @@ type CreateMethodIntegrationInput struct {
 	HttpMethod      string
 	TargetLambdaArn pulumi.StringInput
 	AuthorizerId    *pulumi.StringPtrInput
+	// Default CUSTOM con AuthorizerId, NONE senza
+	AuthorizationType   string
+	AuthorizationScopes []string
+	// method.request.{path|querystring|header}.<name> -> obbligatorio
+	RequestParameters  pulumi.BoolMap
+	RequestValidatorId pulumi.StringPtrInput
+	ApiKeyRequired     bool
 }
 
 type CreateStageInput struct {
 	CreateRestAPI
-	RestApi    *apigateway.RestApi
-	Deploy     *apigateway.Deployment
-	Name       string
-	Tags       pulumi.StringMapInput
-	AllOptions []pulumi.Resource
+	RestApi        *apigateway.RestApi
+	Deploy         *apigateway.Deployment
+	Name           string
+	Tags           pulumi.StringMapInput
+	AllOptions     []pulumi.Resource
+	Throttle       *ApiThrottle
+	MethodSettings []ApiMethodSetting
 }
 
 type CreateAuthorizerInput struct {
 	CreateRestAPI
-	RestApi        *apigateway.RestApi
-	Tags           pulumi.StringMapInput
-	LambdaAuth     *lambda.Function
+	RestApi *apigateway.RestApi
+	Tags    pulumi.StringMapInput
+
+	// Chiave per Methods.Authorizer, anche nei nomi delle risorse
+	Name string
+	Type AuthorizerType // default TOKEN
+	// TOKEN e REQUEST
+	LambdaAuth *lambda.Function
+	// Header del token: TOKEN e COGNITO_USER_POOLS
 	IdentitySource string
+	// REQUEST, es. "method.request.header.Authorization", "method.request.querystring.tenant"
+	IdentitySources []string
+	// Cache del risultato, 0 per disattivarla
+	TtlInSeconds int
+	// ARN degli user pool per COGNITO_USER_POOLS
+	ProviderArns pulumi.StringArrayInput
 }
 
 type CreateOptionsInput struct {
@@ -91,4 +159,77 @@ type CreateOptionsInput struct {
 	BaseResource *apigateway.Resource
 	Endpoint     Endpoints
 	AllOptions   []pulumi.Resource
+	Cors         cors.Config
+}
+
+// OpenAPIIntegration binds an OpenAPI operation to a Lambda proxy integration
+type OpenAPIIntegration struct {
+	TargetLambdaInvokeArn    pulumi.StringInput
+	TargetLambdaFunctionName pulumi.Input
+	TargetLambdaQualifier    pulumi.StringPtrInput
+	// Protetta dall'authorizer di CreateRestAPIInput.LambdaAuth
+	Authorized     bool
+	ApiKeyRequired bool
+}
+
+type OpenAPIInput struct {
+	Document []byte // JSON o YAML
+	// Chiave: operationId, oppure "<METHOD> <path>" (es. "GET /pets/{petId}")
+	Integrations map[string]OpenAPIIntegration
+	// Aggiunge il preflight OPTIONS, con la CORS di CreateRestAPIInput, ai path che non lo definiscono
+	Cors bool
+}
+
+// ApiThrottle limits the requests of a stage, method or usage plan
+type ApiThrottle struct {
+	RateLimit  float64 // richieste al secondo
+	BurstLimit int
+}
+
+// ApiMethodSetting overrides the stage settings ("*/*") for one method
+type ApiMethodSetting struct {
+	Path       string       // es. "/users/{id}"
+	HttpMethod string       // vuoto o "*" per tutti i metodi
+	Throttle   *ApiThrottle // nil: quello dello stage
+	// nil: come lo stage
+	LoggingLevel     *string
+	MetricsEnabled   *bool
+	DataTraceEnabled *bool
+}
+
+type ApiQuota struct {
+	Limit  int
+	Period string // DAY | WEEK | MONTH
+	Offset int
+}
+
+type ApiKeyArgs struct {
+	Name        string
+	Description string
+	// Valore fisso (es. da un secret), generato se nil
+	Value pulumi.StringPtrInput
+}
+
+type UsagePlanArgs struct {
+	Name        string
+	Description string
+	Throttle    *ApiThrottle
+	Quota       *ApiQuota
+	// Throttling per metodo nel piano
+	MethodThrottles []ApiMethodSetting
+	ApiKeys         []ApiKeyArgs
+}
+
+type CreateUsagePlanInput struct {
+	CreateRestAPI
+	RestApi *apigateway.RestApi
+	Stage   *apigateway.Stage
+	Tags    pulumi.StringMapInput
+	UsagePlanArgs
+}
+
+type UsagePlanOutput struct {
+	UsagePlan *apigateway.UsagePlan
+	// Per ApiKeyArgs.Name
+	ApiKeys map[string]*apigateway.ApiKey
 }
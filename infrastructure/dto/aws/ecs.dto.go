@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+import (
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
 
 type EnvVar struct {
 	Name  string             `json:"name"`
@@ -13,6 +17,174 @@ type PortMapping struct {
 	HostPort      int    `json:"hostPort"`      // per Fargate di solito = containerPort
 }
 
+type ContainerSecret struct {
+	Name      string             `json:"name"`
+	ValueFrom pulumi.StringInput `json:"valueFrom"` // ARN Secrets Manager / SSM Parameter Store, letto dall'execution role
+}
+
+type ContainerDependency struct {
+	ContainerName string `json:"containerName"`
+	Condition     string `json:"condition"` // "START" | "COMPLETE" | "SUCCESS" | "HEALTHY"
+}
+
+type ContainerHealthCheck struct {
+	Command     []string `json:"command"` // es. ["CMD-SHELL", "curl -f http://localhost:8080/health || exit 1"]
+	Interval    int      `json:"interval,omitempty"`
+	Timeout     int      `json:"timeout,omitempty"`
+	Retries     int      `json:"retries,omitempty"`
+	StartPeriod int      `json:"startPeriod,omitempty"`
+}
+
+type Ulimit struct {
+	Name      string `json:"name"` // es. "nofile"
+	SoftLimit int    `json:"softLimit"`
+	HardLimit int    `json:"hardLimit"`
+}
+
+type MountPoint struct {
+	SourceVolume  string `json:"sourceVolume"` // nome del volume in ECSInput.Volumes
+	ContainerPath string `json:"containerPath"`
+	ReadOnly      bool   `json:"readOnly"`
+}
+
+type ContainerLogConfiguration struct {
+	LogDriver string            `json:"logDriver"` // "awslogs" | "awsfirelens" | ...
+	Options   map[string]string `json:"options,omitempty"`
+}
+
+type FirelensConfiguration struct {
+	Type    string            `json:"type"` // "fluentbit" | "fluentd"
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ContainerDefinition is a typed ECS container definition.
+// Without LogConfiguration the container logs to the service log group
+// with the container name as stream prefix
+type ContainerDefinition struct {
+	Name      string
+	Image     pulumi.StringInput
+	Essential *bool // default true
+
+	Cpu               int
+	Memory            int
+	MemoryReservation int
+
+	Command          []string
+	EntryPoint       []string
+	WorkingDirectory string
+	User             string
+
+	Environment  []EnvVar
+	Secrets      []ContainerSecret
+	PortMappings []PortMapping
+
+	DependsOn   []ContainerDependency
+	HealthCheck *ContainerHealthCheck
+	Ulimits     []Ulimit
+	MountPoints []MountPoint
+
+	LogConfiguration      *ContainerLogConfiguration
+	FirelensConfiguration *FirelensConfiguration
+
+	ReadonlyRootFilesystem bool
+	StartTimeout           int
+	StopTimeout            int
+	DockerLabels           map[string]string
+}
+
+// ECSSecret injects a secret as container environment variable.
+// Set SecretsManagerArn (with an optional JsonKey) or SsmParameterArn
+type ECSSecret struct {
+	Name              string             // nome della variabile d'ambiente
+	SecretsManagerArn pulumi.StringInput // ARN del secret
+	JsonKey           string             // chiave del secret JSON (opzionale)
+	SsmParameterArn   pulumi.StringInput // ARN del parametro SSM
+	KmsKeyArn         pulumi.StringInput // CMK custom usata per cifrare il valore (opzionale)
+	Container         string             // default: container applicativo
+}
+
+// ECSDeployment configures the rolling deployment of the service.
+// Nil values fall back to defaults selected from ECSInput.Env ("prod"/"production" vs others)
+type ECSDeployment struct {
+	CircuitBreaker        *bool // default true
+	Rollback              *bool // default true in prod, false altrimenti (task falliti ispezionabili)
+	MinimumHealthyPercent *int  // default 100 in prod, 50 altrimenti
+	MaximumPercent        *int  // default 200
+
+	// Rollback automatico se uno di questi allarmi CloudWatch va in ALARM durante il deploy
+	AlarmNames    []string
+	AlarmRollback *bool // default true, indipendente da Rollback
+
+	HealthCheckGracePeriodSeconds *int  // solo con LoadBalancer, default 60
+	EnableExecuteCommand          *bool // default false in prod, true altrimenti
+}
+
+// ECSAutoscaling configures the DesiredCount scaling policies beyond the
+// default CPU / Memory target tracking (TargetCPU, TargetMemory)
+type ECSAutoscaling struct {
+	ScaleInCooldown  int  // secondi, target tracking
+	ScaleOutCooldown int  // secondi, target tracking
+	DisableScaleIn   bool // target tracking solo in scale-out
+	DisableCPU       bool
+	DisableMemory    bool
+
+	// ALB RequestCountPerTarget (richiede ECSInput.LoadBalancer di tipo "application")
+	RequestCountPerTarget float64
+
+	// Target tracking su metriche CloudWatch custom (es. messaggi SQS per task)
+	CustomMetrics []ECSCustomMetricScaling
+
+	// Step scaling attivato da allarmi CloudWatch
+	StepScaling []ECSStepScaling
+
+	// Azioni schedulate (es. scale to zero di notte in dev)
+	Scheduled []ECSScheduledScaling
+}
+
+type ECSCustomMetricScaling struct {
+	Name        string
+	Namespace   string // es. "AWS/SQS"
+	MetricName  string // es. "ApproximateNumberOfMessagesVisible"
+	Statistic   string // default "Average"
+	Unit        string
+	Dimensions  map[string]string // es. {"QueueName": "jobs"}
+	TargetValue float64
+}
+
+type ECSScalingStep struct {
+	LowerBound *float64 // rispetto alla soglia dell'allarme
+	UpperBound *float64
+	Adjustment int
+}
+
+type ECSScalingAlarm struct {
+	Namespace          string
+	MetricName         string
+	Statistic          string // default "Average"
+	Dimensions         map[string]string
+	ComparisonOperator string // es. "GreaterThanOrEqualToThreshold"
+	Threshold          float64
+	EvaluationPeriods  int // default 1
+	Period             int // secondi, default 60
+}
+
+type ECSStepScaling struct {
+	Name                  string
+	AdjustmentType        string // default "ChangeInCapacity"
+	Cooldown              int
+	MetricAggregationType string // default "Average"
+	Steps                 []ECSScalingStep
+	Alarm                 ECSScalingAlarm
+}
+
+type ECSScheduledScaling struct {
+	Name        string
+	Schedule    string // "cron(0 20 ? * MON-FRI *)" | "rate(1 day)" | "at(...)"
+	Timezone    string // es. "Europe/Rome"
+	MinCapacity *int
+	MaxCapacity *int
+}
+
 type DDogSidecar struct {
 	Enable       bool
 	Image        string
@@ -29,6 +201,12 @@ type ECSInput struct {
 	AccountID     string
 	Tags          pulumi.StringMap
 
+	// Cluster esistente (es. output di CreateCluster); se nil viene creato "<baseName>-ecs-cluster"
+	Cluster           *ECSClusterOutput
+	ContainerInsights string // solo per il cluster creato: "enabled" | "enhanced" | "disabled"
+	// Strategie FARGATE / FARGATE_SPOT; se valorizzate sostituiscono il LaunchType FARGATE
+	CapacityProviderStrategies []CapacityProviderStrategy
+
 	// Task
 	EcrImageUrl   pulumi.StringInput
 	TaskCPU       string // "256"
@@ -36,6 +214,18 @@ type ECSInput struct {
 	ContainerPort int
 	HostPort      int
 	EnvVars       []EnvVar
+	Secrets       []ECSSecret // Secrets Manager / SSM, letti dall'execution role
+
+	// Opzioni avanzate del container applicativo. Name, Image, PortMappings ed Environment,
+	// se vuoti, sono presi da "<baseName>-container", EcrImageUrl, ContainerPort/HostPort ed EnvVars
+	AppContainer ContainerDefinition
+	// Container aggiuntivi: log router FireLens, envoy, init container di migrazione...
+	Containers []ContainerDefinition
+	// Volumi del task referenziati dai MountPoints
+	Volumes ecs.TaskDefinitionVolumeArray
+
+	// Deployment (circuit breaker, rollback, ECS Exec); default in base a Env
+	Deployment ECSDeployment
 
 	// Networking
 	SubnetIds        []pulumi.StringInput // public subnets
@@ -48,37 +238,135 @@ type ECSInput struct {
 	MaxCapacity  int
 	TargetCPU    float64 // 60
 	TargetMemory float64 // 80
+	Autoscaling  ECSAutoscaling
 
 	// Logs
 	LogGroupName     string // "/aws/ecs/dev-psicoapp-main"
 	LogRetentionDays int    // 14
 
-	// IAM extra
-	AttachS3FullAccess     bool
-	CustomInlinePolicyJSON *string // inline policy JSON sul taskRole
+	// IAM extra sul taskRole: statement least-privilege (es. S3_MANAGE_FILE sul solo bucket
+	// applicativo) e managed policy ARN
+	TaskRoleStatements        []policy.StatementSpec
+	TaskRoleManagedPolicyArns []string
 
 	// Log subscription → Lambda (opzionale)
 	LogSubscriptionLambdaName *string
 	LogSubscriptionLambdaArn  *string
 
-	// Sidecar opzionale
+	// Sidecar Datadog opzionale (preset di ContainerDefinition)
 	DDog DDogSidecar
 
-	// ---- DNS (opzionale, per collegare Route53 al task pubblico) ----
+	// Load balancer (opzionale): se valorizzato il servizio viene registrato
+	// su un target group "ip" dietro ALB/NLB e il record DNS diventa un alias all'LB
+	LoadBalancer *ECSLoadBalancer
+
+	// ---- DNS (opzionale, per collegare Route53 al task pubblico o all'LB) ----
 	// Zona già esistente (es. Z123ABC...), e recordName tipo "api" o "api.miodominio.com"
 	// Se passi "api", la funzione creerà "api.<zoneName>"
 	HostedZoneId    *string // es. "Z123ABC..."
 	RecordName      *string // es. "api" oppure FQDN "api.miodominio.com"
 	RecordTTL       *int    // default 60
-	BackendPublicIp *string // IP pubblico del task, passato dalla pipeline
+	BackendPublicIp *string // IP pubblico del task, passato dalla pipeline (solo senza LoadBalancer)
+}
+
+// ECSLoadBalancer collega il servizio ECS a un ALB/NLB.
+// L'LB può essere esistente (LoadBalancerArn, DnsName, ZoneId) oppure creato (Create);
+// lo stesso vale per il listener (ListenerArn oppure Listener)
+type ECSLoadBalancer struct {
+	LbType string // "application" (default) | "network"
+
+	// LB esistente
+	LoadBalancerArn     pulumi.StringInput
+	LoadBalancerDnsName pulumi.StringInput
+	LoadBalancerZoneId  pulumi.StringInput
+
+	// Nuovo LB, creato con load_balancer.CreateService
+	Create *LoadBalancerInput
+
+	// Listener esistente, oppure nuovo listener creato con load_balancer.CreateListener
+	ListenerArn pulumi.StringInput
+	Listener    *ListenerInput
+
+	// Target group, TargetType forzato a "ip" (Fargate awsvpc)
+	TargetGroup TargetGroupInput
+
+	// Listener rule (solo ALB)
+	RulePriority int      // default 100
+	PathPatterns []string // default ["/*"]
+	HostHeaders  []string
+}
+
+type CapacityProviderStrategy struct {
+	CapacityProvider string // "FARGATE" | "FARGATE_SPOT"
+	Weight           int
+	Base             int // task minimi sempre su questo provider
+}
+
+// ECSClusterInput describes a cluster shared by several services
+type ECSClusterInput struct {
+	Name              string
+	ContainerInsights string   // default "enabled"
+	CapacityProviders []string // default ["FARGATE", "FARGATE_SPOT"]
+
+	// Strategia di default per i servizi senza CapacityProviderStrategies
+	DefaultCapacityProviderStrategies []CapacityProviderStrategy
+}
+
+type ECSClusterOutput struct {
+	ClusterName pulumi.StringOutput
+	ClusterArn  pulumi.StringOutput
 }
 
 type ECSOutput struct {
 	ClusterName          pulumi.StringOutput
+	ClusterArn           pulumi.StringOutput
 	ServiceName          pulumi.StringOutput
 	ServiceArn           pulumi.StringOutput
 	TaskDefinitionArn    pulumi.StringOutput
 	TaskExecutionRoleArn pulumi.StringOutput
 	TaskRoleArn          pulumi.StringOutput
 	LogGroupName         pulumi.StringOutput
+	LogGroupArn          pulumi.StringOutput
+
+	// Valorizzati solo con ECSInput.LoadBalancer
+	LoadBalancerArn     pulumi.StringOutput
+	LoadBalancerDnsName pulumi.StringOutput
+	ListenerArn         pulumi.StringOutput
+	TargetGroupArn      pulumi.StringOutput
+
+	// Autoscaling target, es. "service/<cluster>/<service>"
+	AutoscalingResourceId pulumi.StringOutput
+}
+
+// ECSScheduledTaskInput describes a Fargate task run by an EventBridge rule (no ECS service).
+// Service-only fields of ECSInput (LoadBalancer, Autoscaling, DNS, DesiredCount) are ignored
+type ECSScheduledTaskInput struct {
+	ECSInput
+
+	// Senza ScheduleExpression viene creata solo la task definition (one-off, es. `aws ecs run-task`)
+	ScheduleExpression string // "cron(0 2 * * ? *)" | "rate(1 day)"
+	Description        string
+	Enabled            *bool  // default true
+	TaskCount          int    // default 1
+	PlatformVersion    string // default "LATEST"
+
+	// Retry / DLQ (la coda deve consentire sqs:SendMessage a events.amazonaws.com)
+	MaximumRetryAttempts     *int
+	MaximumEventAgeInSeconds *int
+	DeadLetterQueueArn       pulumi.StringInput
+}
+
+type ECSScheduledTaskOutput struct {
+	ClusterName          pulumi.StringOutput
+	ClusterArn           pulumi.StringOutput
+	TaskDefinitionArn    pulumi.StringOutput
+	TaskExecutionRoleArn pulumi.StringOutput
+	TaskRoleArn          pulumi.StringOutput
+	LogGroupName         pulumi.StringOutput
+	LogGroupArn          pulumi.StringOutput
+
+	// Valorizzati solo con ScheduleExpression
+	RuleName      pulumi.StringOutput
+	RuleArn       pulumi.StringOutput
+	InvokeRoleArn pulumi.StringOutput
 }
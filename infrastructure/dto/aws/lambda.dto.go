@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -9,8 +10,10 @@ import (
 type LambdaType string
 
 const (
-	LambdaTypeEFS LambdaType = "EFS"
-	LambdaTypeS3  LambdaType = "S3"
+	LambdaTypeEFS   LambdaType = "EFS"
+	LambdaTypeS3    LambdaType = "S3"
+	LambdaTypeImage LambdaType = "IMAGE"
+	LambdaTypeGo    LambdaType = "GO"
 )
 
 type LambdaArgs struct {
@@ -22,6 +25,7 @@ type LambdaArgs struct {
 	Timeout                      int
 	ReservedConcurrentExecutions pulumi.IntPtrInput
 	Layers                       []string
+	LayerArns                    pulumi.StringArray // ARN versionati noti al deploy (es. LayerOutput.Arn)
 	Description                  *string
 	Publish                      pulumi.BoolPtrInput
 	Tags                         pulumi.StringMap
@@ -29,12 +33,20 @@ type LambdaArgs struct {
 	// Statements policy
 	Statements []iam.GetPolicyDocumentStatementArgs
 
+	// IAM Role
+	RoleArn                pulumi.StringInput // ruolo esistente: non vengono creati ruolo e policy
+	ManagedPolicyArns      []string
+	PermissionsBoundaryArn string
+	RolePath               string // default "/"
+
 	// Source code
 	BuildCommand   string
 	WorkingDir     string
 	OutputPath     string
 	SourceCodeHash string
 	ProjectPrefix  string
+	SourceDir      string   // sorgenti da cui calcolare l'hash (default WorkingDir)
+	BuildIgnore    []string // pattern esclusi dall'hash, es. "node_modules", "*.md"
 
 	// Environment variables
 	Environments *lambda.FunctionEnvironmentArgs
@@ -49,6 +61,13 @@ type LambdaArgs struct {
 	// Dead Letter
 	DeadLetterTargetArn *string
 	DeadLetterConfig    *lambda.FunctionDeadLetterConfigArgs
+
+	// Logs (/aws/lambda/<Name>, creato prima della funzione)
+	LogRetentionDays    int                // default 30
+	LogKmsKeyArn        pulumi.StringInput // la key policy deve consentire logs.<region>.amazonaws.com
+	LogFormat           string             // "Text" (default) | "JSON"
+	ApplicationLogLevel string             // solo JSON: "TRACE" | "DEBUG" | "INFO" | "WARN" | "ERROR" | "FATAL"
+	SystemLogLevel      string             // solo JSON: "DEBUG" | "INFO" | "WARN"
 }
 
 type LambdaEFSArgs struct {
@@ -65,9 +84,205 @@ type LambdaS3Input struct {
 	LambdaS3Args
 }
 
+type EventSourceType string
+
+const (
+	EventSourceSQS      EventSourceType = "SQS"
+	EventSourceDynamoDB EventSourceType = "DYNAMODB"
+	EventSourceKinesis  EventSourceType = "KINESIS"
+)
+
+// LambdaEventSource describes a trigger polled by Lambda (lambda.EventSourceMapping)
+type LambdaEventSource struct {
+	Name           string // suffisso del nome risorsa, es. "orders"
+	Type           EventSourceType
+	EventSourceArn pulumi.StringInput // ARN coda SQS o stream DynamoDB/Kinesis
+	Enabled        *bool              // default true
+
+	BatchSize                      int
+	MaximumBatchingWindowInSeconds int
+	ReportBatchItemFailures        bool // partial batch response
+
+	// Solo stream (DynamoDB / Kinesis)
+	StartingPosition           string // "LATEST" (default) | "TRIM_HORIZON"
+	BisectBatchOnFunctionError bool
+	MaximumRetryAttempts       *int
+	MaximumRecordAgeInSeconds  *int
+	ParallelizationFactor      int
+	OnFailureDestinationArn    pulumi.StringInput // coda SQS o topic SNS
+}
+
+// LambdaImageArgs describes a container image Lambda (Runtime/Handler are ignored)
+type LambdaImageArgs struct {
+	// URI ECR con tag ("<repo-url>:<tag>"), oppure solo "<repo-url>" se valorizzato ImageDigest
+	ImageUri    pulumi.StringInput
+	ImageDigest string // "sha256:..."
+
+	// Override della configurazione dell'immagine
+	Command          []string
+	EntryPoint       []string
+	WorkingDirectory string
+}
+
+// LambdaGoArgs describes a Go handler compiled as bootstrap for provided.al2023.
+// WorkingDir is the module root, Architecture selects GOARCH
+type LambdaGoArgs struct {
+	Package   string   // es. "./cmd/orders"
+	BuildTags []string // "lambda.norpc" sempre incluso
+	LdFlags   string   // default "-s -w"
+}
+
+type LambdaGoInput struct {
+	LambdaArgs
+	LambdaGoArgs
+}
+
+type LambdaImageInput struct {
+	LambdaArgs
+	LambdaImageArgs
+}
+
 type ServiceLambdaArgs struct {
 	LambdaArgs
 	*LambdaEFSArgs
 	*LambdaS3Args
+	*LambdaImageArgs
+	*LambdaGoArgs
 	LambdaType LambdaType
+
+	// Trigger (SQS, DynamoDB Streams, Kinesis)
+	EventSources []LambdaEventSource
+
+	// Alias (forza Publish)
+	Alias *LambdaAliasArgs
+
+	// Invocazione asincrona: retry e destinazioni
+	AsyncInvoke *LambdaAsyncInvokeArgs
+
+	// Function URL (sull'alias, se presente)
+	FunctionUrl *LambdaFunctionUrlArgs
+
+	// VPC con security group dedicato (alternativo a LambdaArgs.VpcConfig)
+	Vpc *LambdaVpcArgs
+
+	// CORS delle risposte di network.APIGatewayRes (variabile cors.ENV_VAR), stessa policy di
+	// CreateRestAPIInput.Cors. Se nil le risposte usano network.DEFAULT_HEADERS
+	Cors *cors.Config
+}
+
+// LambdaVpcArgs attaches the function to the VPC with a dedicated security group
+// allowing only the listed egress destinations
+type LambdaVpcArgs struct {
+	VpcId pulumi.StringInput
+	// Subnet esplicite, oppure ricercate nella VPC per tag (es. {"Tier": "private"})
+	SubnetIds  pulumi.StringArray
+	SubnetTags map[string]string
+
+	Egress []LambdaVpcEgress
+	// HTTPS verso 0.0.0.0/0 (API AWS tramite NAT)
+	AllowHttpsEgress bool
+}
+
+// LambdaVpcEgress is an allowed destination, es. the security group of CreatePostgresCluster.
+// With SecurityGroupId the matching ingress is created as a standalone vpc.SecurityGroupIngressRule
+// on the destination: that security group must not declare inline Ingress/Egress rules
+// (es. network.CreateSecurityGroup), or its stack would remove the rule on the next update
+type LambdaVpcEgress struct {
+	Name            string
+	SecurityGroupId pulumi.StringInput // destinazione per SG: crea anche l'ingress sul SG di destinazione
+	CidrBlock       string             // in alternativa a SecurityGroupId
+	Port            int                // obbligatoria
+	Protocol        string             // default "tcp"
+}
+
+type LambdaFunctionUrlArgs struct {
+	AuthType   string // "AWS_IAM" (default) | "NONE"
+	InvokeMode string // "BUFFERED" (default) | "RESPONSE_STREAM"
+	Cors       *LambdaFunctionUrlCors
+}
+
+// Stessa configurazione CORS delle API Gateway: OPTIONS viene escluso e MaxAge
+// non può superare cors.FUNCTION_URL_MAX_AGE (vedi cors.Config.FunctionUrl)
+type LambdaFunctionUrlCors = cors.Config
+
+type LambdaDestinationType string
+
+const (
+	LambdaDestinationSQS         LambdaDestinationType = "SQS"
+	LambdaDestinationSNS         LambdaDestinationType = "SNS"
+	LambdaDestinationEventBridge LambdaDestinationType = "EVENTBRIDGE"
+	LambdaDestinationLambda      LambdaDestinationType = "LAMBDA"
+)
+
+type LambdaDestination struct {
+	Type LambdaDestinationType
+	Arn  pulumi.StringInput // coda, topic, event bus o funzione
+}
+
+// LambdaAsyncInvokeArgs configures the lambda.FunctionEventInvokeConfig (on the alias, if any)
+type LambdaAsyncInvokeArgs struct {
+	MaximumRetryAttempts     *int // 0-2
+	MaximumEventAgeInSeconds *int // 60-21600
+	OnSuccess                *LambdaDestination
+	OnFailure                *LambdaDestination
+}
+
+// LambdaAliasArgs describes the alias published with the function, optionally as a weighted canary
+type LambdaAliasArgs struct {
+	Name        string // es. "live"
+	Description string
+
+	// Versione stabile dell'alias; vuoto = versione pubblicata da questo deploy
+	FunctionVersion string
+	// Canary: quota di traffico (0-1) verso la versione appena pubblicata, richiede FunctionVersion
+	CanaryWeight float64
+
+	ProvisionedConcurrency int
+}
+
+type ServiceLambdaOutput struct {
+	Function *lambda.Function
+	Version  pulumi.StringOutput // versione pubblicata ("$LATEST" senza Publish)
+	Alias    *lambda.Alias       // nil senza Alias
+
+	// ARN da usare nelle integrazioni (alias se presente, altrimenti la funzione)
+	Arn       pulumi.StringOutput
+	InvokeArn pulumi.StringOutput
+	// Qualifier dei lambda.Permission (nome alias, vuoto senza Alias)
+	Qualifier pulumi.StringPtrInput
+
+	// Valorizzato solo con FunctionUrl
+	FunctionUrl pulumi.StringOutput
+	// Valorizzato solo con Vpc
+	SecurityGroupId pulumi.StringOutput
+}
+
+// LayerArgs describes a layer built with the same pipeline of the Lambda sources.
+// OutputPath is the folder containing the runtime directory: its content is zipped relative
+// to it, so "build/layer" with "build/layer/python/..." gives the "python/..." entries
+// ("nodejs/node_modules/..." for Node.js)
+type LayerArgs struct {
+	Name        string
+	Description string
+	LicenseInfo string
+
+	// Source code
+	BuildCommand string
+	WorkingDir   string
+	OutputPath   string // cartella padre di "python/" o "nodejs/", non la cartella stessa
+	SourceDir    string
+	BuildIgnore  []string
+
+	CompatibleRuntimes      []string
+	CompatibleArchitectures []string // "x86_64" | "arm64"
+
+	// Condivisione (lambda:GetLayerVersion)
+	ShareWithAccounts       []string // account ID, "*" per tutti
+	ShareWithOrganizationId string
+}
+
+type LayerOutput struct {
+	LayerVersion *lambda.LayerVersion
+	// ARN versionato, da usare in LambdaArgs.LayerArns
+	Arn pulumi.StringOutput
 }
@@ -12,6 +12,12 @@ type ListenerInput struct {
 	Protocol       string           // var.lb_forward_listener_protocol
 	CertificateArn *string          // var.lb_certificate_arn (usata solo se Protocol == "HTTPS")
 	Tags           pulumi.StringMap // var.tags
+
+	// Opzionali: ARN come output Pulumi (es. LB creato nello stesso stack).
+	// LoadBalancerArn ha precedenza su AwsLbArn; con DefaultTargetGroupArn
+	// la default action diventa "forward" invece del fixed-response 400
+	LoadBalancerArn       pulumi.StringInput
+	DefaultTargetGroupArn pulumi.StringInput
 }
 
 type LoadBalancerInput struct {
@@ -19,7 +25,8 @@ type LoadBalancerInput struct {
 	LbType            string           // var.lb_type ("application" | "network" | "gateway")
 	LbSecurityGroupId *pulumi.IDOutput // var.lb_security_group_id (usata solo se LbType == "application")
 	LbSubnetIds       []string         // var.lb_subnet_ids
-	LogBucket         string           // var.log_bucket
+	LogBucket         string           // var.log_bucket (access logs disabilitati se vuoto)
+	Internal          *bool            // default true
 	Tags              pulumi.StringMap // var.tags
 }
 
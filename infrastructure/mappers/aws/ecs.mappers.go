@@ -0,0 +1,156 @@
+package mappers
+
+import (
+	"encoding/json"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type keyValueJSON struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type secretJSON struct {
+	Name      string `json:"name"`
+	ValueFrom string `json:"valueFrom"`
+}
+
+// containerDefinitionJSON is the resolved ECS container definition schema
+type containerDefinitionJSON struct {
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	Essential bool   `json:"essential"`
+
+	Cpu               int `json:"cpu,omitempty"`
+	Memory            int `json:"memory,omitempty"`
+	MemoryReservation int `json:"memoryReservation,omitempty"`
+
+	Command          []string `json:"command,omitempty"`
+	EntryPoint       []string `json:"entryPoint,omitempty"`
+	WorkingDirectory string   `json:"workingDirectory,omitempty"`
+	User             string   `json:"user,omitempty"`
+
+	Environment  []keyValueJSON    `json:"environment"`
+	Secrets      []secretJSON      `json:"secrets,omitempty"`
+	PortMappings []dto.PortMapping `json:"portMappings"`
+
+	DependsOn   []dto.ContainerDependency `json:"dependsOn,omitempty"`
+	HealthCheck *dto.ContainerHealthCheck `json:"healthCheck,omitempty"`
+	Ulimits     []dto.Ulimit              `json:"ulimits,omitempty"`
+	MountPoints []dto.MountPoint          `json:"mountPoints,omitempty"`
+
+	LogConfiguration      *dto.ContainerLogConfiguration `json:"logConfiguration,omitempty"`
+	FirelensConfiguration *dto.FirelensConfiguration     `json:"firelensConfiguration,omitempty"`
+
+	ReadonlyRootFilesystem bool              `json:"readonlyRootFilesystem,omitempty"`
+	StartTimeout           int               `json:"startTimeout,omitempty"`
+	StopTimeout            int               `json:"stopTimeout,omitempty"`
+	DockerLabels           map[string]string `json:"dockerLabels,omitempty"`
+}
+
+// AWSLogsConfiguration builds the awslogs driver configuration for the given log group
+func AWSLogsConfiguration(logGroupName, region, streamPrefix string) *dto.ContainerLogConfiguration {
+	return &dto.ContainerLogConfiguration{
+		LogDriver: "awslogs",
+		Options: map[string]string{
+			"awslogs-group":         logGroupName,
+			"awslogs-region":        region,
+			"awslogs-stream-prefix": streamPrefix,
+		},
+	}
+}
+
+// DatadogSidecarContainer is the Datadog agent preset built on the generic container model
+func DatadogSidecarContainer(name string, sidecar dto.DDogSidecar, logGroupName, region string) dto.ContainerDefinition {
+	essential := false
+	return dto.ContainerDefinition{
+		Name:             name,
+		Image:            pulumi.String(sidecar.Image),
+		Essential:        &essential,
+		Environment:      sidecar.Env,
+		PortMappings:     sidecar.PortMappings,
+		LogConfiguration: AWSLogsConfiguration(logGroupName, region, "ddog"),
+	}
+}
+
+// ContainerDefinitionsJSON resolves every Pulumi input of the container definitions
+// (images, environment values, secret ARNs) and renders the task definition JSON
+func ContainerDefinitionsJSON(defs []dto.ContainerDefinition) pulumi.StringOutput {
+	inputs := make([]any, 0, len(defs))
+	for _, d := range defs {
+		inputs = append(inputs, inputOrEmpty(d.Image))
+		for _, env := range d.Environment {
+			inputs = append(inputs, inputOrEmpty(env.Value))
+		}
+		for _, secret := range d.Secrets {
+			inputs = append(inputs, inputOrEmpty(secret.ValueFrom))
+		}
+	}
+
+	return pulumi.All(inputs...).ApplyT(func(values []any) (string, error) {
+		next := func() string {
+			v, _ := values[0].(string)
+			values = values[1:]
+			return v
+		}
+
+		out := make([]containerDefinitionJSON, 0, len(defs))
+		for _, d := range defs {
+			essential := true
+			if d.Essential != nil {
+				essential = *d.Essential
+			}
+
+			c := containerDefinitionJSON{
+				Name:                   d.Name,
+				Image:                  next(),
+				Essential:              essential,
+				Cpu:                    d.Cpu,
+				Memory:                 d.Memory,
+				MemoryReservation:      d.MemoryReservation,
+				Command:                d.Command,
+				EntryPoint:             d.EntryPoint,
+				WorkingDirectory:       d.WorkingDirectory,
+				User:                   d.User,
+				Environment:            make([]keyValueJSON, 0, len(d.Environment)),
+				PortMappings:           d.PortMappings,
+				DependsOn:              d.DependsOn,
+				HealthCheck:            d.HealthCheck,
+				Ulimits:                d.Ulimits,
+				MountPoints:            d.MountPoints,
+				LogConfiguration:       d.LogConfiguration,
+				FirelensConfiguration:  d.FirelensConfiguration,
+				ReadonlyRootFilesystem: d.ReadonlyRootFilesystem,
+				StartTimeout:           d.StartTimeout,
+				StopTimeout:            d.StopTimeout,
+				DockerLabels:           d.DockerLabels,
+			}
+			if c.PortMappings == nil {
+				c.PortMappings = []dto.PortMapping{}
+			}
+			for _, env := range d.Environment {
+				c.Environment = append(c.Environment, keyValueJSON{Name: env.Name, Value: next()})
+			}
+			for _, secret := range d.Secrets {
+				c.Secrets = append(c.Secrets, secretJSON{Name: secret.Name, ValueFrom: next()})
+			}
+
+			out = append(out, c)
+		}
+
+		b, err := json.Marshal(out)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}).(pulumi.StringOutput)
+}
+
+func inputOrEmpty(in pulumi.StringInput) pulumi.StringInput {
+	if in == nil {
+		return pulumi.String("")
+	}
+	return in
+}
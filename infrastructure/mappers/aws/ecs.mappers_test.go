@@ -0,0 +1,96 @@
+package mappers
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type mocks struct{}
+
+func (mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+// resolve attende il valore dell'output fuori da un programma Pulumi
+func resolve(t *testing.T, build func() pulumi.StringOutput) string {
+	t.Helper()
+	var got string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		build().ApplyT(func(s string) error {
+			got = s
+			wg.Done()
+			return nil
+		})
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	return got
+}
+
+func TestContainerDefinitionsJSON(t *testing.T) {
+	essential := false
+	defs := []dto.ContainerDefinition{
+		{
+			Name:        "app",
+			Image:       pulumi.String("repo/app:1"),
+			Environment: []dto.EnvVar{{Name: "MODE", Value: pulumi.String("api")}},
+			Secrets:     []dto.ContainerSecret{{Name: "TOKEN", ValueFrom: pulumi.Sprintf("arn:aws:ssm:eu-west-1:123456789012:parameter/%s", "token")}},
+		},
+		{
+			Name:      "sidecar",
+			Essential: &essential,
+			Environment: []dto.EnvVar{
+				{Name: "EMPTY"},
+				{Name: "LEVEL", Value: pulumi.String("debug")},
+			},
+			PortMappings: []dto.PortMapping{{Protocol: "tcp", ContainerPort: 8126, HostPort: 8126}},
+		},
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(resolve(t, func() pulumi.StringOutput { return ContainerDefinitionsJSON(defs) })), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		index int
+		key   string
+		want  any
+	}{
+		{name: "image", index: 0, key: "image", want: "repo/app:1"},
+		{name: "default essential", index: 0, key: "essential", want: true},
+		{name: "environment", index: 0, key: "environment", want: []any{map[string]any{"name": "MODE", "value": "api"}}},
+		{name: "secrets", index: 0, key: "secrets", want: []any{map[string]any{"name": "TOKEN", "valueFrom": "arn:aws:ssm:eu-west-1:123456789012:parameter/token"}}},
+		{name: "empty port mappings", index: 0, key: "portMappings", want: []any{}},
+		{name: "missing image", index: 1, key: "image", want: ""},
+		{name: "not essential", index: 1, key: "essential", want: false},
+		{name: "environment order", index: 1, key: "environment", want: []any{
+			map[string]any{"name": "EMPTY", "value": ""},
+			map[string]any{"name": "LEVEL", "value": "debug"},
+		}},
+		{name: "no secrets", index: 1, key: "secrets", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := got[tt.index][tt.key]; !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("container %d %s = %#v, want %#v", tt.index, tt.key, v, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mappers
+
+import (
+	"fmt"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// LambdaLogGroupName is the log group Lambda writes to by default
+func LambdaLogGroupName(fnName string) string {
+	return fmt.Sprintf("/aws/lambda/%s", fnName)
+}
+
+// LambdaLoggingConfig maps the log format and levels of the function.
+// Log levels are only supported with the JSON format, which is then implied
+func LambdaLoggingConfig(args dto.LambdaArgs) *lambda.FunctionLoggingConfigArgs {
+	format := args.LogFormat
+	if format == "" {
+		if args.ApplicationLogLevel == "" && args.SystemLogLevel == "" {
+			format = "Text"
+		} else {
+			format = "JSON"
+		}
+	}
+
+	cfg := &lambda.FunctionLoggingConfigArgs{
+		LogFormat: pulumi.String(format),
+		LogGroup:  pulumi.String(LambdaLogGroupName(args.Name)),
+	}
+	if args.ApplicationLogLevel != "" {
+		cfg.ApplicationLogLevel = pulumi.String(args.ApplicationLogLevel)
+	}
+	if args.SystemLogLevel != "" {
+		cfg.SystemLogLevel = pulumi.String(args.SystemLogLevel)
+	}
+	return cfg
+}
+
+// LambdaLayers merges the static layer ARNs with the ones known at deploy time
+func LambdaLayers(layers []string, layerArns pulumi.StringArray) pulumi.StringArray {
+	return append(pulumi.ToStringArray(layers), layerArns...)
+}
@@ -0,0 +1,295 @@
+package apigw_openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
+	"gopkg.in/yaml.v3"
+)
+
+// HTTP methods of an OpenAPI path item, in the order they are processed
+var HTTP_METHODS = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+const (
+	INTEGRATION_EXTENSION = "x-amazon-apigateway-integration"
+	AUTHORIZER_EXTENSION  = "x-amazon-apigateway-authorizer"
+	AUTHTYPE_EXTENSION    = "x-amazon-apigateway-authtype"
+	API_KEY_SCHEME        = "api_key"
+)
+
+// Operation is an operation of the document bound to an integration
+type Operation struct {
+	// operationId, or "<METHOD> <path>" when missing
+	Key    string
+	Method string // upper case
+	Path   string
+}
+
+type Integration struct {
+	// Lambda invoke ARN (function or alias)
+	Uri        string
+	Authorized bool
+	// Richiede l'header x-api-key di un usage plan
+	ApiKeyRequired bool
+}
+
+type Authorizer struct {
+	Name         string
+	Uri          string
+	Credentials  string
+	HeaderName   string // es. "Authorization"
+	TtlInSeconds int
+}
+
+type Options struct {
+	// Integrations by Operation.Key
+	Integrations map[string]Integration
+	Authorizer   *Authorizer
+	// Adds an OPTIONS mock integration to the paths without one
+	Cors *cors.Config
+}
+
+type document = map[string]any
+
+// Parse decodes an OpenAPI 3 document, JSON or YAML
+func Parse(doc []byte) (document, error) {
+	var out document
+	trimmed := bytes.TrimSpace(doc)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &out); err != nil {
+			return nil, fmt.Errorf("openapi: invalid JSON document: %w", err)
+		}
+	} else {
+		var raw any
+		if err := yaml.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("openapi: invalid YAML document: %w", err)
+		}
+		out, _ = normalizeYAML(raw).(map[string]any)
+	}
+
+	version, _ := out["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version %q, want 3.x", version)
+	}
+	if _, ok := out["paths"].(map[string]any); !ok {
+		return nil, errors.New("openapi: missing paths")
+	}
+
+	return out, nil
+}
+
+// Operations returns the operations of the document without an explicit integration, sorted by path and method
+func Operations(doc []byte) ([]Operation, error) {
+	parsed, err := Parse(doc)
+	if err != nil {
+		return nil, err
+	}
+	return operations(parsed), nil
+}
+
+func operations(doc document) []Operation {
+	paths := doc["paths"].(map[string]any)
+
+	var out []Operation
+	for _, path := range sortedKeys(paths) {
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, method := range HTTP_METHODS {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			if _, ok := op[INTEGRATION_EXTENSION]; ok {
+				continue
+			}
+
+			key, _ := op["operationId"].(string)
+			if key == "" {
+				key = strings.ToUpper(method) + " " + path
+			}
+			out = append(out, Operation{Key: key, Method: strings.ToUpper(method), Path: path})
+		}
+	}
+	return out
+}
+
+// Render injects the Lambda proxy integrations, the authorizer and the CORS preflights
+// and returns the JSON document for the RestApi Body
+func Render(doc []byte, opts Options) (string, error) {
+	parsed, err := Parse(doc)
+	if err != nil {
+		return "", err
+	}
+	paths := parsed["paths"].(map[string]any)
+
+	used := make(map[string]struct{}, len(opts.Integrations))
+	apiKeys := false
+	for _, op := range operations(parsed) {
+		integration, ok := opts.Integrations[op.Key]
+		if !ok {
+			return "", fmt.Errorf("openapi: operation %q has no integration", op.Key)
+		}
+		used[op.Key] = struct{}{}
+
+		node := paths[op.Path].(map[string]any)[strings.ToLower(op.Method)].(map[string]any)
+		node[INTEGRATION_EXTENSION] = map[string]any{
+			"type":                "aws_proxy",
+			"httpMethod":          "POST",
+			"uri":                 integration.Uri,
+			"passthroughBehavior": "when_no_match",
+		}
+		security := map[string]any{}
+		if integration.Authorized {
+			if opts.Authorizer == nil {
+				return "", fmt.Errorf("openapi: operation %q requires an authorizer", op.Key)
+			}
+			security[opts.Authorizer.Name] = []any{}
+		}
+		if integration.ApiKeyRequired {
+			apiKeys = true
+			security[API_KEY_SCHEME] = []any{}
+		}
+		// Un solo requirement: authorizer e API key devono valere entrambi
+		if len(security) != 0 {
+			node["security"] = []any{security}
+		}
+	}
+	for key := range opts.Integrations {
+		if _, ok := used[key]; !ok {
+			return "", fmt.Errorf("openapi: integration %q does not match any operation", key)
+		}
+	}
+
+	if apiKeys {
+		securitySchemes(parsed)[API_KEY_SCHEME] = map[string]any{
+			"type": "apiKey",
+			"name": "x-api-key",
+			"in":   "header",
+		}
+	}
+
+	if opts.Authorizer != nil {
+		securitySchemes(parsed)[opts.Authorizer.Name] = map[string]any{
+			"type":             "apiKey",
+			"name":             opts.Authorizer.HeaderName,
+			"in":               "header",
+			AUTHTYPE_EXTENSION: "custom",
+			AUTHORIZER_EXTENSION: map[string]any{
+				"type":                         "token",
+				"authorizerUri":                opts.Authorizer.Uri,
+				"authorizerCredentials":        opts.Authorizer.Credentials,
+				"authorizerResultTtlInSeconds": opts.Authorizer.TtlInSeconds,
+				"identitySource":               "method.request.header." + opts.Authorizer.HeaderName,
+			},
+		}
+	}
+
+	if opts.Cors != nil {
+		for _, path := range sortedKeys(paths) {
+			item, ok := paths[path].(map[string]any)
+			if !ok {
+				continue
+			}
+			if _, ok := item["options"]; ok {
+				continue
+			}
+			item["options"] = corsPreflight(*opts.Cors)
+		}
+	}
+
+	// encoding/json ordina le chiavi: stesso input, stesso Body
+	out, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func securitySchemes(doc document) map[string]any {
+	components, _ := doc["components"].(map[string]any)
+	if components == nil {
+		components = map[string]any{}
+		doc["components"] = components
+	}
+	schemes, _ := components["securitySchemes"].(map[string]any)
+	if schemes == nil {
+		schemes = map[string]any{}
+		components["securitySchemes"] = schemes
+	}
+	return schemes
+}
+
+func corsPreflight(policy cors.Config) map[string]any {
+	params := policy.PreflightParameters()
+	headers := make(map[string]any, len(params))
+	for param := range params {
+		headers[strings.TrimPrefix(param, "method.response.header.")] = map[string]any{"schema": map[string]any{"type": "string"}}
+	}
+
+	response := map[string]any{
+		"statusCode":         "200",
+		"responseParameters": params,
+	}
+	if tpl := policy.PreflightTemplate(); tpl != "" {
+		response["responseTemplates"] = map[string]any{"application/json": tpl}
+	}
+
+	return map[string]any{
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "CORS preflight",
+				"headers":     headers,
+			},
+		},
+		INTEGRATION_EXTENSION: map[string]any{
+			"type":                "mock",
+			"passthroughBehavior": "when_no_match",
+			"requestTemplates": map[string]any{
+				"application/json": `{"statusCode": 200}`,
+			},
+			"responses": map[string]any{
+				"default": response,
+			},
+		},
+	}
+}
+
+// normalizeYAML converts non-string keys (es. response codes `200:`) to be JSON encodable
+func normalizeYAML(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = normalizeYAML(val)
+		}
+		return t
+	case map[any]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return out
+	case []any:
+		for i, val := range t {
+			t[i] = normalizeYAML(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,149 @@
+package apigw_openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
+)
+
+const petsYAML = `
+openapi: 3.0.1
+info:
+  title: pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        200:
+          description: ok
+    post:
+      responses:
+        201:
+          description: created
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      responses:
+        200:
+          description: ok
+  /health:
+    get:
+      x-amazon-apigateway-integration:
+        type: mock
+`
+
+func TestOperations(t *testing.T) {
+	ops, err := Operations([]byte(petsYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Operation{
+		{Key: "listPets", Method: "GET", Path: "/pets"},
+		{Key: "POST /pets", Method: "POST", Path: "/pets"},
+		{Key: "getPet", Method: "GET", Path: "/pets/{petId}"},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("Operations() = %v, want %v", ops, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	opts := Options{
+		Integrations: map[string]Integration{
+			"listPets":   {Uri: "uri-list", ApiKeyRequired: true},
+			"POST /pets": {Uri: "uri-create", Authorized: true},
+			"getPet":     {Uri: "uri-get", Authorized: true},
+		},
+		Authorizer: &Authorizer{Name: "lambda-auth", Uri: "uri-auth", Credentials: "role", HeaderName: "Authorization"},
+		Cors:       &cors.Config{AllowOrigins: []string{"*"}, AllowMethods: []string{"GET", "POST", "OPTIONS"}, AllowHeaders: []string{"*"}},
+	}
+
+	body, err := Render([]byte(petsYAML), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Render([]byte(petsYAML), opts)
+	if body != again {
+		t.Error("Render should be deterministic")
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+	paths := doc["paths"].(map[string]any)
+
+	create := paths["/pets"].(map[string]any)["post"].(map[string]any)
+	if uri := create[INTEGRATION_EXTENSION].(map[string]any)["uri"]; uri != "uri-create" {
+		t.Errorf("POST /pets uri = %v", uri)
+	}
+	if _, ok := create["security"]; !ok {
+		t.Error("POST /pets should be authorized")
+	}
+	list := paths["/pets"].(map[string]any)["get"].(map[string]any)
+	if security := list["security"].([]any); len(security) != 1 || len(security[0].(map[string]any)) != 1 {
+		t.Errorf("GET /pets should only require the API key: %v", security)
+	}
+
+	for _, path := range []string{"/pets", "/pets/{petId}", "/health"} {
+		if _, ok := paths[path].(map[string]any)["options"]; !ok {
+			t.Errorf("%s should have the CORS preflight", path)
+		}
+	}
+	if !strings.Contains(body, `"'GET,POST,OPTIONS'"`) {
+		t.Error("CORS methods should be quoted")
+	}
+
+	schemes := doc["components"].(map[string]any)["securitySchemes"].(map[string]any)
+	if _, ok := schemes["lambda-auth"]; !ok {
+		t.Error("authorizer security scheme missing")
+	}
+	if _, ok := schemes[API_KEY_SCHEME]; !ok {
+		t.Error("API key security scheme missing")
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		opts Options
+	}{
+		{
+			name: "missing integration",
+			doc:  petsYAML,
+			opts: Options{Integrations: map[string]Integration{"listPets": {Uri: "u"}}},
+		},
+		{
+			name: "unknown integration",
+			doc:  petsYAML,
+			opts: Options{Integrations: map[string]Integration{
+				"listPets": {Uri: "u"}, "POST /pets": {Uri: "u"}, "getPet": {Uri: "u"}, "deletePet": {Uri: "u"},
+			}},
+		},
+		{
+			name: "authorized without authorizer",
+			doc:  petsYAML,
+			opts: Options{Integrations: map[string]Integration{
+				"listPets": {Uri: "u", Authorized: true}, "POST /pets": {Uri: "u"}, "getPet": {Uri: "u"},
+			}},
+		},
+		{
+			name: "swagger 2",
+			doc:  `{"swagger": "2.0", "paths": {}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Render([]byte(tt.doc), tt.opts); err == nil {
+				t.Error("Render() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package apigw_paths
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	staticSegment = regexp.MustCompile(`^[A-Za-z0-9._~:@!$&'()*,;=-]+$`)
+	paramSegment  = regexp.MustCompile(`^\{([A-Za-z0-9_.-]+)(\+?)\}$`)
+)
+
+// Resource is a node of the API Gateway resource tree
+type Resource struct {
+	Path       string // es. "/users/{id}"
+	ParentPath string // "/" for the root children
+	PathPart   string // es. "{id}"
+}
+
+// Split normalizes the path and returns its segments, validating "{param}" and "{proxy+}"
+func Split(path string) ([]string, error) {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("apigw path %q: empty path", path)
+	}
+
+	segments := strings.Split(trimmed, "/")
+	for i, s := range segments {
+		if m := paramSegment.FindStringSubmatch(s); m != nil {
+			// {proxy+} cattura il resto del path: deve essere l'ultimo segmento
+			if m[2] == "+" && i != len(segments)-1 {
+				return nil, fmt.Errorf("apigw path %q: greedy segment %s must be the last one", path, s)
+			}
+			continue
+		}
+		if !staticSegment.MatchString(s) {
+			return nil, fmt.Errorf("apigw path %q: invalid segment %q", path, s)
+		}
+	}
+	return segments, nil
+}
+
+// Normalize returns the path in the "/a/b" form
+func Normalize(path string) (string, error) {
+	segments, err := Split(path)
+	if err != nil {
+		return "", err
+	}
+	return "/" + strings.Join(segments, "/"), nil
+}
+
+// Tree returns the resources needed by the paths, shared parents only once,
+// with every parent before its children
+func Tree(paths []string) ([]Resource, error) {
+	seen := make(map[string]struct{})
+	// Parametro dichiarato per ogni parent: API Gateway non ammette {id} e {userId} fratelli
+	params := make(map[string]string)
+
+	var out []Resource
+	for _, path := range paths {
+		segments, err := Split(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parent := "/"
+		for _, s := range segments {
+			current := strings.TrimSuffix(parent, "/") + "/" + s
+			if paramSegment.MatchString(s) {
+				if other, ok := params[parent]; ok && other != s {
+					return nil, fmt.Errorf("apigw path %q: %s conflicts with %s under %s", path, s, other, parent)
+				}
+				params[parent] = s
+			}
+			if _, ok := seen[current]; !ok {
+				seen[current] = struct{}{}
+				out = append(out, Resource{Path: current, ParentPath: parent, PathPart: s})
+			}
+			parent = current
+		}
+	}
+	return out, nil
+}
+
+// PathParameters returns the parameter names of the path, es. "{proxy+}" -> "proxy"
+func PathParameters(path string) []string {
+	var out []string
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if m := paramSegment.FindStringSubmatch(s); m != nil {
+			out = append(out, m[1])
+		}
+	}
+	return out
+}
+
+// RequestParameters returns the Method RequestParameters: the path parameters are always
+// required, query and header ones map the name to the required flag
+func RequestParameters(path string, query map[string]bool, headers map[string]bool) map[string]bool {
+	out := make(map[string]bool)
+	for _, p := range PathParameters(path) {
+		out["method.request.path."+p] = true
+	}
+	for name, required := range query {
+		out["method.request.querystring."+name] = required
+	}
+	for name, required := range headers {
+		out["method.request.header."+name] = required
+	}
+	return out
+}
+
+// SourceArnPath converts the path to the execute-api ARN form ("{id}" -> "*")
+func SourceArnPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = "*"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// MethodPath returns the MethodSettings path of a method, es. ("/users/{id}", "get") -> "users/{id}/GET".
+// "*" matches any resource or method
+func MethodPath(path string, method string) string {
+	resource := strings.Trim(path, "/")
+	if resource == "" {
+		resource = "*"
+	}
+	if method == "" {
+		method = "*"
+	}
+	return resource + "/" + strings.ToUpper(method)
+}
+
+// ThrottlePath returns the usage plan throttle path of a method, es. "/users/{id}/GET"
+func ThrottlePath(path string, method string) string {
+	return "/" + MethodPath(path, method)
+}
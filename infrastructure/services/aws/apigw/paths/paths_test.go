@@ -0,0 +1,75 @@
+package apigw_paths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	got, err := Split("/users/{id}/orders/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users", "{id}", "orders"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %v, want %v", got, want)
+	}
+
+	for _, path := range []string{"", "/", "/files/{proxy+}/meta", "/a b", "/users/{}"} {
+		if _, err := Split(path); err == nil {
+			t.Errorf("Split(%q) should fail", path)
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	got, err := Tree([]string{"users", "/users/{id}/orders", "/users/{id}", "/files/{proxy+}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Resource{
+		{Path: "/users", ParentPath: "/", PathPart: "users"},
+		{Path: "/users/{id}", ParentPath: "/users", PathPart: "{id}"},
+		{Path: "/users/{id}/orders", ParentPath: "/users/{id}", PathPart: "orders"},
+		{Path: "/files", ParentPath: "/", PathPart: "files"},
+		{Path: "/files/{proxy+}", ParentPath: "/files", PathPart: "{proxy+}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %+v, want %+v", got, want)
+	}
+
+	// Parametri diversi sullo stesso livello
+	if _, err := Tree([]string{"/users/{id}", "/users/{userId}/orders"}); err == nil {
+		t.Error("Tree() should fail on sibling path parameters")
+	}
+}
+
+func TestRequestParameters(t *testing.T) {
+	got := RequestParameters("/users/{id}/files/{proxy+}", map[string]bool{"page": false}, map[string]bool{"X-Tenant": true})
+	want := map[string]bool{
+		"method.request.path.id":          true,
+		"method.request.path.proxy":       true,
+		"method.request.querystring.page": false,
+		"method.request.header.X-Tenant":  true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RequestParameters() = %v, want %v", got, want)
+	}
+}
+
+func TestSourceArnPath(t *testing.T) {
+	if got := SourceArnPath("/pets/{petId}/toys/{proxy+}"); got != "/pets/*/toys/*" {
+		t.Errorf("SourceArnPath() = %s", got)
+	}
+}
+
+func TestMethodPath(t *testing.T) {
+	if got := MethodPath("/users/{id}/", "get"); got != "users/{id}/GET" {
+		t.Errorf("MethodPath() = %s", got)
+	}
+	if got := MethodPath("", ""); got != "*/*" {
+		t.Errorf("MethodPath() = %s", got)
+	}
+	if got := ThrottlePath("users", "POST"); got != "/users/POST" {
+		t.Errorf("ThrottlePath() = %s", got)
+	}
+}
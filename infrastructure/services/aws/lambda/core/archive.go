@@ -1,22 +1,385 @@
 package lambda_core
 
 import (
-	"log"
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+// Default cache folder of the built archives, relative to WorkingDir
+const DEFAULT_BUILD_CACHE_DIR = ".pulumi-build"
+
+// zipEpoch is the fixed modification time of every zip entry
+var zipEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type BuildOptions struct {
+	BuildCommand string
+	WorkingDir   string
+	// Build artifact (file, directory or .zip), relative to the Pulumi program like WorkingDir
+	OutputPath string
+
+	// Sources hashed to decide whether to rebuild (default WorkingDir)
+	SourceDir string
+	// Glob patterns (filepath.Match) on the relative path or base name, es. "node_modules", "*.md"
+	IgnorePatterns []string
+	// Folder of the cached archives (default WorkingDir/.pulumi-build/<OutputPath key>), one per
+	// function: archives of other hashes are pruned
+	CacheDir string
+}
+
+type BuildResult struct {
+	Archive pulumi.Archive
+	ZipPath string
+	// Hash of the sources + build command, name of the cached archive
+	SourceHash string
+	// Base64 SHA-256 of the archive, for lambda.FunctionArgs.SourceCodeHash
+	SourceCodeHash string
+	// False when the cached archive was reused
+	Built bool
+}
+
+// BuildSourceZip runs the build command and returns the reproducible archive of outputPath
 func BuildSourceZip(ctx *pulumi.Context, buildCmd string, workingDir string, outputPath string) (pulumi.AssetOrArchiveInput, error) {
-	if buildCmd != "" {
-		cmd := exec.Command("sh", "-c", buildCmd)
-		cmd.Dir = workingDir
-		out, err := cmd.CombinedOutput()
+	res, err := BuildSource(ctx, BuildOptions{
+		BuildCommand: buildCmd,
+		WorkingDir:   workingDir,
+		OutputPath:   outputPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Archive, nil
+}
+
+// BuildSource hashes the source tree and runs the build only if no archive is cached for that hash.
+// The archive has fixed timestamps and permissions, so the same sources give the same SourceCodeHash
+func BuildSource(ctx *pulumi.Context, opts BuildOptions) (*BuildResult, error) {
+	if opts.OutputPath == "" {
+		return nil, errors.New("lambda build: OutputPath is required")
+	}
+	sourceDir := opts.SourceDir
+	if sourceDir == "" {
+		sourceDir = opts.WorkingDir
+	}
+	if sourceDir == "" {
+		sourceDir = "."
+	}
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		// Una cartella per artefatto: il prune non tocca gli archivi delle altre funzioni
+		key, err := outputCacheKey(opts.WorkingDir, opts.OutputPath)
 		if err != nil {
-			log.Println("Build Lambda Error", "err: ", err)
 			return nil, err
 		}
-		log.Println("build command success: " + string(out))
+		cacheDir = filepath.Join(opts.WorkingDir, DEFAULT_BUILD_CACHE_DIR, key)
+	}
+
+	// Artefatto e cache (anche delle altre funzioni) non fanno parte dei sorgenti
+	excluded := []string{cacheDir, opts.OutputPath}
+	ignore := append(slices.Clone(opts.IgnorePatterns), DEFAULT_BUILD_CACHE_DIR)
+	sourceHash, err := HashSourceDir(sourceDir, ignore, excluded, opts.BuildCommand)
+	if err != nil {
+		logError(ctx, fmt.Sprintf("lambda build: hashing %s: %v", sourceDir, err))
+		return nil, err
+	}
+
+	res := &BuildResult{
+		ZipPath:    filepath.Join(cacheDir, sourceHash+".zip"),
+		SourceHash: sourceHash,
+	}
+
+	if _, err := os.Stat(res.ZipPath); err == nil {
+		logInfo(ctx, fmt.Sprintf("lambda build: sources unchanged (%s), reusing %s", sourceHash[:12], res.ZipPath))
+	} else {
+		if opts.BuildCommand != "" {
+			cmd := exec.Command("sh", "-c", opts.BuildCommand)
+			cmd.Dir = opts.WorkingDir
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				logError(ctx, fmt.Sprintf("lambda build: %q failed: %v\n%s", opts.BuildCommand, err, out))
+				return nil, err
+			}
+			logInfo(ctx, fmt.Sprintf("lambda build: %q succeeded\n%s", opts.BuildCommand, out))
+		}
+
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			return nil, err
+		}
+		if err := WriteReproducibleZip(opts.OutputPath, res.ZipPath); err != nil {
+			logError(ctx, fmt.Sprintf("lambda build: zipping %s: %v", opts.OutputPath, err))
+			return nil, err
+		}
+		pruneCache(ctx, cacheDir, res.ZipPath)
+		res.Built = true
+	}
+
+	res.SourceCodeHash, err = fileSHA256Base64(res.ZipPath)
+	if err != nil {
+		return nil, err
+	}
+	res.Archive = pulumi.NewFileArchive(res.ZipPath)
+
+	return res, nil
+}
+
+// outputCacheKey names the default cache folder of an artifact after its path relative to
+// workingDir, so it does not depend on the checkout location
+func outputCacheKey(workingDir string, outputPath string) (string, error) {
+	root, err := filepath.Abs(orDot(workingDir))
+	if err != nil {
+		return "", err
+	}
+	output, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, output)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(rel)))
+	return "output-" + hex.EncodeToString(sum[:6]), nil
+}
+
+// HashSourceDir returns the hex SHA-256 of the relative paths, executable bits and contents of the
+// files under dir (sorted, ignoring patterns and excluded paths), salted with extra
+func HashSourceDir(dir string, ignore []string, excluded []string, extra ...string) (string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	var skip []string
+	for _, e := range excluded {
+		if abs, err := filepath.Abs(e); err == nil {
+			skip = append(skip, abs)
+		}
+	}
+
+	h := sha256.New()
+	for _, e := range extra {
+		fmt.Fprintf(h, "extra:%s\x00", e)
+	}
+
+	// WalkDir visita in ordine lessicale: l'hash è deterministico
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(skip, path) || isIgnored(filepath.ToSlash(rel), ignore) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "file:%s:%t\x00", filepath.ToSlash(rel), info.Mode()&0o111 != 0)
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteReproducibleZip zips src (file, directory or existing .zip) into dst with fixed
+// timestamps and permissions, preserving only the executable bit
+func WriteReproducibleZip(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(out)
+
+	switch {
+	case info.IsDir():
+		err = zipDir(zw, src)
+	case strings.EqualFold(filepath.Ext(src), ".zip"):
+		err = rezip(zw, src)
+	default:
+		err = zipFile(zw, filepath.Base(src), info.Mode(), func() (io.ReadCloser, error) { return os.Open(src) })
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+func zipDir(zw *zip.Writer, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return zipFile(zw, filepath.ToSlash(rel), info.Mode(), func() (io.ReadCloser, error) { return os.Open(path) })
+	})
+}
+
+// rezip normalizes an archive produced by the build command
+func rezip(zw *zip.Writer, src string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	files := slices.Clone(zr.File)
+	slices.SortFunc(files, func(a, b *zip.File) int { return strings.Compare(a.Name, b.Name) })
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := zipFile(zw, f.Name, f.Mode(), f.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func zipFile(zw *zip.Writer, name string, mode fs.FileMode, open func() (io.ReadCloser, error)) error {
+	perm := fs.FileMode(0o644)
+	if mode&0o111 != 0 {
+		perm = 0o755
+	}
+
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: zipEpoch,
+	}
+	hdr.SetMode(perm)
+
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	r, err := open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func isIgnored(rel string, patterns []string) bool {
+	base := pathBase(rel)
+	for _, p := range patterns {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func pathBase(rel string) string {
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		return rel[i+1:]
+	}
+	return rel
+}
+
+// pruneCache removes the archives of previous builds
+func pruneCache(ctx *pulumi.Context, cacheDir string, keep string) {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(cacheDir, e.Name())
+		if e.IsDir() || path == keep || filepath.Ext(e.Name()) != ".zip" {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			logInfo(ctx, fmt.Sprintf("lambda build: cannot remove stale archive %s: %v", path, err))
+		}
+	}
+}
+
+func fileSHA256Base64(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// Il contesto è nil fuori da un programma Pulumi (es. test)
+func logInfo(ctx *pulumi.Context, msg string) {
+	if ctx != nil {
+		_ = ctx.Log.Info(msg, nil)
+	}
+}
+
+func logError(ctx *pulumi.Context, msg string) {
+	if ctx != nil {
+		_ = ctx.Log.Error(msg, nil)
 	}
-	return pulumi.NewFileArchive(outputPath), nil
 }
@@ -0,0 +1,198 @@
+package lambda_core
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashSourceDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main")
+	writeFile(t, filepath.Join(dir, "node_modules", "dep.js"), "v1")
+	writeFile(t, filepath.Join(dir, "README.md"), "docs")
+
+	ignore := []string{"node_modules", "*.md"}
+	first, err := HashSourceDir(dir, ignore, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// File ignorati: l'hash non cambia
+	writeFile(t, filepath.Join(dir, "node_modules", "dep.js"), "v2")
+	writeFile(t, filepath.Join(dir, "README.md"), "more docs")
+	if got, _ := HashSourceDir(dir, ignore, nil); got != first {
+		t.Errorf("hash changed on ignored files: %s != %s", got, first)
+	}
+
+	// mtime non influisce
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := HashSourceDir(dir, ignore, nil); got != first {
+		t.Errorf("hash changed on mtime: %s != %s", got, first)
+	}
+
+	if got, _ := HashSourceDir(dir, ignore, nil, "other build command"); got == first {
+		t.Error("hash should depend on the extra values")
+	}
+
+	writeFile(t, filepath.Join(dir, "main.go"), "package main // changed")
+	if got, _ := HashSourceDir(dir, ignore, nil); got == first {
+		t.Error("hash should change on source changes")
+	}
+}
+
+func TestWriteReproducibleZip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "dist")
+	writeFile(t, filepath.Join(src, "index.js"), "exports.handler = async () => {}")
+	writeFile(t, filepath.Join(src, "lib", "util.js"), "module.exports = {}")
+
+	first := filepath.Join(dir, "first.zip")
+	if err := WriteReproducibleZip(src, first); err != nil {
+		t.Fatal(err)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(src, "index.js"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	second := filepath.Join(dir, "second.zip")
+	if err := WriteReproducibleZip(src, second); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if !bytes.Equal(a, b) {
+		t.Error("archives of the same sources should be identical")
+	}
+
+	// Anche un .zip prodotto dalla build viene normalizzato
+	third := filepath.Join(dir, "third.zip")
+	if err := WriteReproducibleZip(first, third); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := os.ReadFile(third)
+	if !bytes.Equal(a, c) {
+		t.Error("re-zipped archive should be identical")
+	}
+}
+
+func TestBuildSourceSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "handler.py"), "def handler(event, context): pass")
+
+	// Il log delle build sta fuori dai sorgenti
+	logPath := filepath.Join(t.TempDir(), "builds.log")
+	opts := BuildOptions{
+		BuildCommand: "mkdir -p out && cp handler.py out/ && echo built >> " + logPath,
+		WorkingDir:   dir,
+		OutputPath:   filepath.Join(dir, "out"),
+	}
+
+	first, err := BuildSource(nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Built || first.SourceCodeHash == "" {
+		t.Fatalf("first build: %+v", first)
+	}
+
+	second, err := BuildSource(nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Built {
+		t.Error("unchanged sources should reuse the cached archive")
+	}
+	if second.SourceCodeHash != first.SourceCodeHash {
+		t.Errorf("SourceCodeHash changed: %s != %s", second.SourceCodeHash, first.SourceCodeHash)
+	}
+
+	writeFile(t, filepath.Join(dir, "handler.py"), "def handler(event, context): return 1")
+	third, err := BuildSource(nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !third.Built || third.SourceCodeHash == first.SourceCodeHash {
+		t.Error("changed sources should be rebuilt")
+	}
+	if _, err := os.Stat(first.ZipPath); !os.IsNotExist(err) {
+		t.Error("stale archive should be pruned")
+	}
+
+	log, _ := os.ReadFile(logPath)
+	if n := strings.Count(string(log), "built"); n != 2 {
+		t.Errorf("build command ran %d times, want 2", n)
+	}
+}
+
+func TestBuildSourceSharedWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users", "index.js"), "exports.handler = async () => 'users'")
+	writeFile(t, filepath.Join(dir, "orders", "index.js"), "exports.handler = async () => 'orders'")
+
+	// Due funzioni nella stessa WorkingDir, cache di default
+	users, err := BuildSource(nil, BuildOptions{WorkingDir: dir, SourceDir: filepath.Join(dir, "users"), OutputPath: filepath.Join(dir, "users")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := BuildSource(nil, BuildOptions{WorkingDir: dir, SourceDir: filepath.Join(dir, "orders"), OutputPath: filepath.Join(dir, "orders")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Dir(users.ZipPath) == filepath.Dir(orders.ZipPath) {
+		t.Errorf("outputs share the cache folder %s", filepath.Dir(users.ZipPath))
+	}
+	for _, res := range []*BuildResult{users, orders} {
+		if _, err := os.Stat(res.ZipPath); err != nil {
+			t.Errorf("archive %s should still exist: %v", res.ZipPath, err)
+		}
+	}
+}
+
+func TestWriteReproducibleZipEntryNames(t *testing.T) {
+	dir := t.TempDir()
+	// Layout di un layer: OutputPath è la cartella padre di python/
+	layer := filepath.Join(dir, "layer")
+	writeFile(t, filepath.Join(layer, "python", "requests", "__init__.py"), "")
+	writeFile(t, filepath.Join(layer, "python", "six.py"), "")
+
+	dst := filepath.Join(dir, "layer.zip")
+	if err := WriteReproducibleZip(layer, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+	}
+	if want := []string{"python/requests/__init__.py", "python/six.py"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package lambda_core
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Runtime and handler of the Go Lambdas (OS-only runtime, binary named bootstrap)
+const (
+	GO_LAMBDA_RUNTIME = "provided.al2023"
+	GO_LAMBDA_HANDLER = "bootstrap"
+)
+
+type GoBuildOptions struct {
+	// Function name, separates the cache of Lambdas sharing the module
+	Name string
+	// Module root, where `go build` runs
+	WorkingDir string
+	// Package of the handler, relative to WorkingDir, es. "./cmd/orders"
+	Package string
+	// Lambda architecture: "arm64" | "x86_64" (default)
+	Architecture string
+	Tags         []string
+	LdFlags      string // default "-s -w"
+
+	IgnorePatterns []string
+	CacheDir       string
+}
+
+// GoArch maps the Lambda architecture to GOARCH
+func GoArch(architecture string) (string, error) {
+	switch architecture {
+	case "", "x86_64":
+		return "amd64", nil
+	case "arm64":
+		return "arm64", nil
+	default:
+		return "", fmt.Errorf("lambda go build: unsupported architecture %q", architecture)
+	}
+}
+
+// GoBuildCommand returns the cross compilation command writing the bootstrap binary in outputDir
+func GoBuildCommand(opts GoBuildOptions, outputDir string) (string, error) {
+	goarch, err := GoArch(opts.Architecture)
+	if err != nil {
+		return "", err
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "."
+	}
+	ldflags := opts.LdFlags
+	if ldflags == "" {
+		ldflags = "-s -w"
+	}
+	// lambda.norpc: il runtime provided non usa il server RPC di aws-lambda-go
+	tags := slices.Clone(opts.Tags)
+	if !slices.Contains(tags, "lambda.norpc") {
+		tags = append(tags, "lambda.norpc")
+	}
+
+	// -buildvcs=false: revisione e stato del repo nel binario cambierebbero SourceCodeHash ad ogni commit
+	return fmt.Sprintf("CGO_ENABLED=0 GOOS=linux GOARCH=%s go build -trimpath -buildvcs=false -tags %s -ldflags %s -o %s %s",
+		goarch,
+		shellQuote(strings.Join(tags, ",")),
+		shellQuote(ldflags),
+		shellQuote(filepath.Join(outputDir, GO_LAMBDA_HANDLER)),
+		shellQuote(pkg),
+	), nil
+}
+
+// BuildGoLambda compiles the handler package as bootstrap and zips it, skipping the build
+// when the module sources are unchanged (see BuildSource)
+func BuildGoLambda(ctx *pulumi.Context, opts GoBuildOptions) (*BuildResult, error) {
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(opts.WorkingDir, DEFAULT_BUILD_CACHE_DIR, opts.Name)
+	}
+	// Il binario va nella cache, esclusa dall'hash dei sorgenti
+	outputDir := filepath.Join(cacheDir, "bin")
+
+	// Il comando entra nell'hash: path relativo alla WorkingDir, non dipende dalla posizione del checkout
+	workingDir, err := filepath.Abs(orDot(opts.WorkingDir))
+	if err != nil {
+		return nil, err
+	}
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	relOutputDir, err := filepath.Rel(workingDir, absOutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := GoBuildCommand(opts, relOutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildSource(ctx, BuildOptions{
+		BuildCommand:   cmd,
+		WorkingDir:     opts.WorkingDir,
+		OutputPath:     filepath.Join(outputDir, GO_LAMBDA_HANDLER),
+		IgnorePatterns: opts.IgnorePatterns,
+		CacheDir:       cacheDir,
+	})
+}
+
+func orDot(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
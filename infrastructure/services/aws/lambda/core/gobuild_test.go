@@ -0,0 +1,97 @@
+package lambda_core
+
+import (
+	"archive/zip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoBuildCommand(t *testing.T) {
+	cmd, err := GoBuildCommand(GoBuildOptions{
+		Package:      "./cmd/orders",
+		Architecture: "arm64",
+		Tags:         []string{"prod"},
+	}, "/tmp/out")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"GOOS=linux", "GOARCH=arm64", "-buildvcs=false", "-tags 'prod,lambda.norpc'", "-ldflags '-s -w'", "-o '/tmp/out/bootstrap'", "'./cmd/orders'"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command %q should contain %q", cmd, want)
+		}
+	}
+
+	if _, err := GoBuildCommand(GoBuildOptions{Architecture: "armv7"}, "/tmp/out"); err == nil {
+		t.Error("unsupported architecture should fail")
+	}
+}
+
+func TestBuildGoLambda(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/fn\n\ngo 1.24\n")
+	writeFile(t, filepath.Join(dir, "cmd", "fn", "main.go"), "package main\n\nfunc main() {}\n")
+
+	res, err := BuildGoLambda(nil, GoBuildOptions{
+		Name:         "fn",
+		WorkingDir:   dir,
+		Package:      "./cmd/fn",
+		Architecture: "arm64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(res.ZipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 1 || zr.File[0].Name != GO_LAMBDA_HANDLER {
+		t.Fatalf("archive should only contain %s", GO_LAMBDA_HANDLER)
+	}
+	if zr.File[0].Mode()&0o111 == 0 {
+		t.Error("bootstrap should be executable")
+	}
+
+	// Il binario nella cache non cambia l'hash dei sorgenti
+	again, err := BuildGoLambda(nil, GoBuildOptions{
+		Name:         "fn",
+		WorkingDir:   dir,
+		Package:      "./cmd/fn",
+		Architecture: "arm64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Built || again.SourceCodeHash != res.SourceCodeHash {
+		t.Error("unchanged module should reuse the cached archive")
+	}
+	if _, err := os.Stat(filepath.Join(dir, DEFAULT_BUILD_CACHE_DIR, "fn")); err != nil {
+		t.Error(err)
+	}
+
+	// Stessi sorgenti in un altro checkout: stesso hash
+	other := t.TempDir()
+	writeFile(t, filepath.Join(other, "go.mod"), "module example.com/fn\n\ngo 1.24\n")
+	writeFile(t, filepath.Join(other, "cmd", "fn", "main.go"), "package main\n\nfunc main() {}\n")
+	moved, err := BuildGoLambda(nil, GoBuildOptions{
+		Name:         "fn",
+		WorkingDir:   other,
+		Package:      "./cmd/fn",
+		Architecture: "arm64",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.SourceHash != res.SourceHash || moved.SourceCodeHash != res.SourceCodeHash {
+		t.Error("the hashes should not depend on the checkout path")
+	}
+}
@@ -1,31 +1,43 @@
 package lambda
 
 import (
+	"path/filepath"
+
 	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
 	lambda_core "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/lambda/core"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-func CreateLambdaEFS(ctx *pulumi.Context, args dto.LambdaArgs, roleArn pulumi.StringInput) (*lambda.Function, error) {
+func CreateLambdaEFS(ctx *pulumi.Context, args dto.LambdaArgs, roleArn pulumi.StringInput, opts ...pulumi.ResourceOption) (*lambda.Function, error) {
 
-	archive, err := lambda_core.BuildSourceZip(ctx, args.BuildCommand, args.WorkingDir, args.OutputPath)
+	build, err := lambda_core.BuildSource(ctx, lambda_core.BuildOptions{
+		BuildCommand:   args.BuildCommand,
+		WorkingDir:     args.WorkingDir,
+		OutputPath:     args.OutputPath,
+		SourceDir:      args.SourceDir,
+		IgnorePatterns: args.BuildIgnore,
+		CacheDir:       filepath.Join(args.WorkingDir, lambda_core.DEFAULT_BUILD_CACHE_DIR, args.Name),
+	})
 	if err != nil {
 		return nil, err
 	}
 	fnArgs := &lambda.FunctionArgs{
-		Name:          pulumi.String(args.Name),
-		Description:   pulumi.StringPtrFromPtr(args.Description),
-		Role:          roleArn,
-		Runtime:       pulumi.StringPtr(args.Runtime),
-		Handler:       pulumi.StringPtr(args.Handler),
-		MemorySize:    pulumi.IntPtr(args.MemorySize),
-		Timeout:       pulumi.IntPtr(args.Timeout),
-		Code:          archive.(pulumi.ArchiveInput),
-		Architectures: pulumi.StringArray{pulumi.String(args.Architecture)},
-		Layers:        pulumi.ToStringArray(args.Layers),
-		VpcConfig:     args.VpcConfig,
-		Tags:          args.Tags,
+		Name:           pulumi.String(args.Name),
+		Description:    pulumi.StringPtrFromPtr(args.Description),
+		Role:           roleArn,
+		Runtime:        pulumi.StringPtr(args.Runtime),
+		Handler:        pulumi.StringPtr(args.Handler),
+		MemorySize:     pulumi.IntPtr(args.MemorySize),
+		Timeout:        pulumi.IntPtr(args.Timeout),
+		Code:           build.Archive,
+		SourceCodeHash: pulumi.String(build.SourceCodeHash),
+		Architectures:  pulumi.StringArray{pulumi.String(args.Architecture)},
+		Layers:         mappers.LambdaLayers(args.Layers, args.LayerArns),
+		VpcConfig:      args.VpcConfig,
+		LoggingConfig:  mappers.LambdaLoggingConfig(args),
+		Tags:           args.Tags,
 	}
 
 	if args.Description != nil {
@@ -54,7 +66,7 @@ func CreateLambdaEFS(ctx *pulumi.Context, args dto.LambdaArgs, roleArn pulumi.St
 		}
 	}
 
-	resp, err := lambda.NewFunction(ctx, args.Name, fnArgs)
+	resp, err := lambda.NewFunction(ctx, args.Name, fnArgs, opts...)
 	if err != nil {
 		return nil, err
 	}
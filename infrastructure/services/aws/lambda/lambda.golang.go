@@ -0,0 +1,71 @@
+package lambda
+
+import (
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
+	lambda_core "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/lambda/core"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func CreateLambdaGo(ctx *pulumi.Context, args dto.LambdaGoInput, roleArn pulumi.StringInput, opts ...pulumi.ResourceOption) (*lambda.Function, error) {
+	architecture := args.Architecture
+	if architecture == "" {
+		architecture = "x86_64"
+	}
+
+	build, err := lambda_core.BuildGoLambda(ctx, lambda_core.GoBuildOptions{
+		Name:           args.Name,
+		WorkingDir:     args.WorkingDir,
+		Package:        args.Package,
+		Architecture:   architecture,
+		Tags:           args.BuildTags,
+		LdFlags:        args.LdFlags,
+		IgnorePatterns: args.BuildIgnore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := args.Runtime
+	if runtime == "" {
+		runtime = lambda_core.GO_LAMBDA_RUNTIME
+	}
+
+	fnArgs := &lambda.FunctionArgs{
+		Name:                         pulumi.String(args.Name),
+		Description:                  pulumi.StringPtrFromPtr(args.Description),
+		Role:                         roleArn,
+		Runtime:                      pulumi.StringPtr(runtime),
+		Handler:                      pulumi.StringPtr(lambda_core.GO_LAMBDA_HANDLER),
+		MemorySize:                   pulumi.IntPtr(args.MemorySize),
+		Timeout:                      pulumi.IntPtr(args.Timeout),
+		Code:                         build.Archive,
+		SourceCodeHash:               pulumi.String(build.SourceCodeHash),
+		Architectures:                pulumi.StringArray{pulumi.String(architecture)},
+		Layers:                       mappers.LambdaLayers(args.Layers, args.LayerArns),
+		ReservedConcurrentExecutions: args.ReservedConcurrentExecutions,
+		Environment:                  args.Environments,
+		TracingConfig:                args.TracingConfig,
+		VpcConfig:                    args.VpcConfig,
+		DeadLetterConfig:             args.DeadLetterConfig,
+		LoggingConfig:                mappers.LambdaLoggingConfig(args.LambdaArgs),
+		Tags:                         args.LambdaArgs.Tags,
+	}
+
+	if args.Publish != nil {
+		fnArgs.Publish = args.Publish
+	}
+	if args.TracingMode != nil {
+		fnArgs.TracingConfig = &lambda.FunctionTracingConfigArgs{
+			Mode: pulumi.String(*args.TracingMode),
+		}
+	}
+	if args.DeadLetterTargetArn != nil {
+		fnArgs.DeadLetterConfig = &lambda.FunctionDeadLetterConfigArgs{
+			TargetArn: pulumi.String(*args.DeadLetterTargetArn),
+		}
+	}
+
+	return lambda.NewFunction(ctx, args.Name, fnArgs, opts...)
+}
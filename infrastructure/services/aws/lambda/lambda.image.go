@@ -0,0 +1,69 @@
+package lambda
+
+import (
+	"errors"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func CreateLambdaImage(ctx *pulumi.Context, args dto.LambdaImageInput, roleArn pulumi.StringInput, opts ...pulumi.ResourceOption) (*lambda.Function, error) {
+	if args.ImageUri == nil {
+		return nil, errors.New("lambda image: ImageUri is required")
+	}
+
+	imageUri := args.ImageUri
+	if args.ImageDigest != "" {
+		imageUri = pulumi.Sprintf("%s@%s", args.ImageUri, args.ImageDigest)
+	}
+
+	// Runtime e Handler non sono ammessi con PackageType "Image"
+	fnArgs := &lambda.FunctionArgs{
+		PackageType:                  pulumi.String("Image"),
+		ImageUri:                     imageUri,
+		Name:                         pulumi.String(args.Name),
+		Description:                  pulumi.StringPtrFromPtr(args.Description),
+		Role:                         roleArn,
+		MemorySize:                   pulumi.IntPtr(args.MemorySize),
+		Timeout:                      pulumi.IntPtr(args.Timeout),
+		ReservedConcurrentExecutions: args.ReservedConcurrentExecutions,
+		Architectures:                pulumi.StringArray{pulumi.String(args.Architecture)},
+		Environment:                  args.Environments,
+		TracingConfig:                args.TracingConfig,
+		VpcConfig:                    args.VpcConfig,
+		DeadLetterConfig:             args.DeadLetterConfig,
+		LoggingConfig:                mappers.LambdaLoggingConfig(args.LambdaArgs),
+		Tags:                         args.Tags,
+	}
+
+	if len(args.Command) > 0 || len(args.EntryPoint) > 0 || args.WorkingDirectory != "" {
+		imageConfig := &lambda.FunctionImageConfigArgs{}
+		if len(args.Command) > 0 {
+			imageConfig.Commands = pulumi.ToStringArray(args.Command)
+		}
+		if len(args.EntryPoint) > 0 {
+			imageConfig.EntryPoints = pulumi.ToStringArray(args.EntryPoint)
+		}
+		if args.WorkingDirectory != "" {
+			imageConfig.WorkingDirectory = pulumi.String(args.WorkingDirectory)
+		}
+		fnArgs.ImageConfig = imageConfig
+	}
+	if args.Publish != nil {
+		fnArgs.Publish = args.Publish
+	}
+	if args.TracingMode != nil {
+		fnArgs.TracingConfig = &lambda.FunctionTracingConfigArgs{
+			Mode: pulumi.String(*args.TracingMode),
+		}
+	}
+	if args.DeadLetterTargetArn != nil {
+		fnArgs.DeadLetterConfig = &lambda.FunctionDeadLetterConfigArgs{
+			TargetArn: pulumi.String(*args.DeadLetterTargetArn),
+		}
+	}
+
+	return lambda.NewFunction(ctx, args.Name, fnArgs, opts...)
+}
@@ -2,11 +2,12 @@ package lambda
 
 import (
 	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-func CreateLambdaS3(ctx *pulumi.Context, args dto.LambdaS3Input, roleArn pulumi.StringInput) (*lambda.Function, error) {
+func CreateLambdaS3(ctx *pulumi.Context, args dto.LambdaS3Input, roleArn pulumi.StringInput, opts ...pulumi.ResourceOption) (*lambda.Function, error) {
 	fnArgs := &lambda.FunctionArgs{
 		S3Bucket:                     pulumi.StringPtr(args.S3Bucket),
 		S3Key:                        pulumi.StringPtr(args.S3Key),
@@ -20,17 +21,21 @@ func CreateLambdaS3(ctx *pulumi.Context, args dto.LambdaS3Input, roleArn pulumi.
 		Timeout:                      pulumi.IntPtr(args.Timeout),
 		ReservedConcurrentExecutions: args.ReservedConcurrentExecutions,
 		Architectures:                pulumi.StringArray{pulumi.String(args.Architecture)},
-		Layers:                       pulumi.ToStringArray(args.Layers),
+		Layers:                       mappers.LambdaLayers(args.Layers, args.LayerArns),
 		Environment:                  args.Environments,
 		TracingConfig:                args.TracingConfig,
 		VpcConfig:                    args.VpcConfig,
 		DeadLetterConfig:             args.DeadLetterConfig,
+		LoggingConfig:                mappers.LambdaLoggingConfig(args.LambdaArgs),
 		Tags:                         args.Tags,
 	}
 
 	if args.Description != nil {
 		fnArgs.Description = pulumi.StringPtrFromPtr(args.Description)
 	}
+	if args.Publish != nil {
+		fnArgs.Publish = args.Publish
+	}
 	if args.ReservedConcurrentExecutions != nil {
 		fnArgs.ReservedConcurrentExecutions = args.ReservedConcurrentExecutions
 	}
@@ -47,5 +52,5 @@ func CreateLambdaS3(ctx *pulumi.Context, args dto.LambdaS3Input, roleArn pulumi.
 		fnArgs.DeadLetterConfig = args.DeadLetterConfig
 	}
 
-	return lambda.NewFunction(ctx, args.Name, fnArgs)
+	return lambda.NewFunction(ctx, args.Name, fnArgs, opts...)
 }
@@ -15,22 +15,33 @@ func CreateListener(ctx *pulumi.Context, in dto.ListenerInput) (*lb.Listener, er
 		cert = pulumi.StringPtr(*in.CertificateArn)
 	}
 
+	var lbArn pulumi.StringInput = pulumi.String(in.AwsLbArn)
+	if in.LoadBalancerArn != nil {
+		lbArn = in.LoadBalancerArn
+	}
+
+	defaultAction := &lb.ListenerDefaultActionArgs{
+		Type: pulumi.String("fixed-response"),
+		FixedResponse: &lb.ListenerDefaultActionFixedResponseArgs{
+			ContentType: pulumi.String("text/plain"),
+			MessageBody: pulumi.StringPtr("FORWARD ERROR"),
+			StatusCode:  pulumi.String("400"),
+		},
+	}
+	if in.DefaultTargetGroupArn != nil {
+		defaultAction = &lb.ListenerDefaultActionArgs{
+			Type:           pulumi.String("forward"),
+			TargetGroupArn: in.DefaultTargetGroupArn,
+		}
+	}
+
 	listener, err := lb.NewListener(ctx, fmt.Sprintf("%s-listener", in.Name), &lb.ListenerArgs{
-		LoadBalancerArn: pulumi.String(in.AwsLbArn),
+		LoadBalancerArn: lbArn,
 		Port:            pulumi.Int(in.Port),
 		Protocol:        pulumi.String(in.Protocol),
 		CertificateArn:  cert,
-		DefaultActions: lb.ListenerDefaultActionArray{
-			&lb.ListenerDefaultActionArgs{
-				Type: pulumi.String("fixed-response"),
-				FixedResponse: &lb.ListenerDefaultActionFixedResponseArgs{
-					ContentType: pulumi.String("text/plain"),
-					MessageBody: pulumi.StringPtr("FORWARD ERROR"),
-					StatusCode:  pulumi.String("400"),
-				},
-			},
-		},
-		Tags: in.Tags,
+		DefaultActions:  lb.ListenerDefaultActionArray{defaultAction},
+		Tags:            in.Tags,
 	})
 	if err != nil {
 		return nil, err
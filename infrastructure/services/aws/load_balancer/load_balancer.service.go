@@ -7,9 +7,9 @@ import (
 )
 
 // CreateService crea l'ALB/NLB con gli stessi valori del Terraform dato.
-// - internal = true
+// - internal = true (salvo Internal = false)
 // - enable_deletion_protection = false
-// - access_logs abilitati su LogBucket con prefix = LbName
+// - access_logs abilitati su LogBucket con prefix = LbName (se LogBucket è valorizzato)
 // - security_groups solo se LbType == "application"
 func CreateService(ctx *pulumi.Context, in dto.LoadBalancerInput) (*lb.LoadBalancer, error) {
 	// security_groups: solo per "application"
@@ -19,10 +19,18 @@ func CreateService(ctx *pulumi.Context, in dto.LoadBalancerInput) (*lb.LoadBalan
 	}
 
 	// access_logs
-	accessLogs := &lb.LoadBalancerAccessLogsArgs{
-		Bucket:  pulumi.String(in.LogBucket),
-		Prefix:  pulumi.StringPtr(in.LbName),
-		Enabled: pulumi.Bool(true),
+	var accessLogs *lb.LoadBalancerAccessLogsArgs
+	if in.LogBucket != "" {
+		accessLogs = &lb.LoadBalancerAccessLogsArgs{
+			Bucket:  pulumi.String(in.LogBucket),
+			Prefix:  pulumi.StringPtr(in.LbName),
+			Enabled: pulumi.Bool(true),
+		}
+	}
+
+	internal := true
+	if in.Internal != nil {
+		internal = *in.Internal
 	}
 
 	// tags fallback
@@ -33,7 +41,7 @@ func CreateService(ctx *pulumi.Context, in dto.LoadBalancerInput) (*lb.LoadBalan
 
 	lbRes, err := lb.NewLoadBalancer(ctx, in.LbName, &lb.LoadBalancerArgs{
 		Name:                     pulumi.String(in.LbName),
-		Internal:                 pulumi.Bool(true),
+		Internal:                 pulumi.Bool(internal),
 		LoadBalancerType:         pulumi.String(in.LbType),
 		SecurityGroups:           sgs,                                  // nil se non applicabile
 		Subnets:                  pulumi.ToStringArray(in.LbSubnetIds), // obbligatorio
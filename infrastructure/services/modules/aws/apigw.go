@@ -1,10 +1,15 @@
 package vtech_aws
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
 	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	apigw_paths "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/apigw/paths"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/apigateway"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -15,36 +20,83 @@ import (
 )
 
 func (mod AWSModule) CreateRestAPI(input dto.CreateRestAPIInput) error {
-	restApi, err := apigateway.NewRestApi(mod.Ctx, fmt.Sprintf("%s-rest-api", input.BaseName), &apigateway.RestApiArgs{
-		Name: pulumi.StringPtr(fmt.Sprintf("%s-rest-api", input.BaseName)),
-		Tags: input.Tags,
-	})
-	if err != nil {
-		slog.Error("Failed to Create Rest API", "err: ", err)
-		return err
+	var restApi *apigateway.RestApi
+	var deployTriggers pulumi.StringMap
+	var err error
+	allOptions := make([]pulumi.Resource, 0)
+
+	if input.Cors != nil {
+		if err := input.Cors.Validate(); err != nil {
+			return err
+		}
 	}
 
-	allOptions := make([]pulumi.Resource, 0)
-	if len(input.Endpoints) != 0 {
-		allOptions, err = mod.CreateEndpoints(dto.CreateEndpointsInput{
-			RestApi:    restApi,
-			Endpoints:  input.Endpoints,
-			AllOptions: allOptions,
-			CreateRestAPI: dto.CreateRestAPI{
-				BaseName:  input.BaseName,
-				Region:    input.Region,
-				AccountId: input.AccountId,
-			},
-		},
-		)
+	if input.OpenAPI != nil {
+		// Con OpenAPI l'authorizer è quello del documento (LambdaAuth)
+		if len(input.Authorizers) != 0 {
+			return fmt.Errorf("rest api %s: Authorizers is not supported with OpenAPI, use LambdaAuth", input.BaseName)
+		}
+		restApi, deployTriggers, err = mod.createOpenAPIRestApi(input)
+		if err != nil {
+			slog.Error("Failed to Create OpenAPI Rest API", "err: ", err)
+			return err
+		}
+	} else {
+		restApi, err = apigateway.NewRestApi(mod.Ctx, fmt.Sprintf("%s-rest-api", input.BaseName), &apigateway.RestApiArgs{
+			Name: pulumi.StringPtr(fmt.Sprintf("%s-rest-api", input.BaseName)),
+			Tags: input.Tags,
+		})
 		if err != nil {
-			slog.Error("Failed to Create API Endpoints", "err: ", err)
+			slog.Error("Failed to Create Rest API", "err: ", err)
 			return err
 		}
+
+		authorizers := make(map[string]dto.ApiAuthorizer, len(input.Authorizers))
+		for _, auth := range input.Authorizers {
+			if auth.Name == "" || auth.Name == dto.NO_AUTHORIZER {
+				return fmt.Errorf("rest api %s: invalid authorizer Name %q", input.BaseName, auth.Name)
+			}
+			if _, ok := authorizers[auth.Name]; ok {
+				return fmt.Errorf("rest api %s: duplicate authorizer %s", input.BaseName, auth.Name)
+			}
+			auth.CreateRestAPI = input.CreateRestAPI
+			auth.RestApi = restApi
+			auth.Tags = input.Tags
+			authorizer, err := mod.CreateAuthorizer(auth)
+			if err != nil {
+				return err
+			}
+			authType := auth.Type
+			if authType == "" {
+				authType = dto.AuthorizerTypeToken
+			}
+			authorizers[auth.Name] = dto.ApiAuthorizer{Authorizer: authorizer, Type: authType}
+		}
+
+		if len(input.Endpoints) != 0 {
+			allOptions, err = mod.CreateEndpoints(dto.CreateEndpointsInput{
+				RestApi:     restApi,
+				Endpoints:   input.Endpoints,
+				AllOptions:  allOptions,
+				Cors:        input.Cors,
+				Authorizers: authorizers,
+				CreateRestAPI: dto.CreateRestAPI{
+					BaseName:  input.BaseName,
+					Region:    input.Region,
+					AccountId: input.AccountId,
+				},
+			},
+			)
+			if err != nil {
+				slog.Error("Failed to Create API Endpoints", "err: ", err)
+				return err
+			}
+		}
 	}
 
 	deploy, err := apigateway.NewDeployment(mod.Ctx, fmt.Sprintf("%s-deploy", input.BaseName), &apigateway.DeploymentArgs{
-		RestApi: restApi.ID(),
+		RestApi:  restApi.ID(),
+		Triggers: deployTriggers,
 	}, pulumi.DependsOn(allOptions))
 	if err != nil {
 		slog.Error("Failed to Create API Deploy", "err: ", err)
@@ -149,21 +201,24 @@ func (mod AWSModule) CreateRestAPI(input dto.CreateRestAPIInput) error {
 		return err
 	}
 
-	_, err = apigateway.NewMethodSettings(mod.Ctx, "methodSettings", &apigateway.MethodSettingsArgs{
-		RestApi:   restApi.ID(),
-		StageName: stage.StageName,
-		Settings: apigateway.MethodSettingsSettingsArgs{
-			LoggingLevel:     pulumi.String("INFO"),
-			MetricsEnabled:   pulumi.Bool(true),
-			DataTraceEnabled: pulumi.Bool(true),
-		},
-		MethodPath: pulumi.String("*/*"),
-	})
+	err = mod.createMethodSettings(input.BaseName, "methodSettings", restApi, stage, input.Throttle, input.MethodSettings)
 	if err != nil {
-		slog.Error("Failed to Create API Stage Method settings", "err", err)
 		return err
 	}
 
+	for _, plan := range input.UsagePlans {
+		_, err = mod.CreateUsagePlan(dto.CreateUsagePlanInput{
+			CreateRestAPI: input.CreateRestAPI,
+			RestApi:       restApi,
+			Stage:         stage,
+			Tags:          input.Tags,
+			UsagePlanArgs: plan,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	_, err = apigateway.NewBasePathMapping(mod.Ctx, fmt.Sprintf("%s-path-mapping", input.BaseName), &apigateway.BasePathMappingArgs{
 		RestApi:    restApi.ID(),
 		StageName:  stage.StageName,
@@ -183,46 +238,125 @@ func (mod AWSModule) CreateEndpoints(input dto.CreateEndpointsInput) ([]pulumi.R
 		return nil, err
 	}
 
-	for i := 0; i < len(input.Endpoints); i++ {
-		endpoint := input.Endpoints[i]
-		baseResource, err := apigateway.NewResource(mod.Ctx, endpoint.Name, &apigateway.ResourceArgs{
+	// Albero delle risorse: i segmenti comuni (es. /users per /users e /users/{id}) sono creati una volta sola
+	paths := make([]string, 0, len(input.Endpoints))
+	for _, endpoint := range input.Endpoints {
+		paths = append(paths, endpoint.Path)
+	}
+	tree, err := apigw_paths.Tree(paths)
+	if err != nil {
+		slog.Error("Invalid endpoint paths", "err", err)
+		return nil, err
+	}
+
+	// Le risorse foglia mantengono il nome dell'endpoint
+	names := make(map[string]string, len(input.Endpoints))
+	for _, endpoint := range input.Endpoints {
+		path, _ := apigw_paths.Normalize(endpoint.Path)
+		if _, ok := names[path]; !ok {
+			names[path] = endpoint.Name
+		}
+	}
+
+	resources := make(map[string]*apigateway.Resource, len(tree))
+	for _, node := range tree {
+		parentId := input.RestApi.RootResourceId
+		if parent, ok := resources[node.ParentPath]; ok {
+			parentId = parent.ID().ToStringOutput()
+		}
+		name, ok := names[node.Path]
+		if !ok {
+			name = fmt.Sprintf("%s-resource%s", input.BaseName, node.Path)
+		}
+		resource, err := apigateway.NewResource(mod.Ctx, name, &apigateway.ResourceArgs{
 			RestApi:  input.RestApi.ID(),
-			ParentId: input.RestApi.RootResourceId,
-			PathPart: pulumi.String(endpoint.Path),
+			ParentId: parentId,
+			PathPart: pulumi.String(node.PathPart),
 		})
 		if err != nil {
 			slog.Error("Failed to Create Resource Rest API", "err: ", err)
 			return nil, err
 		}
-		integrationResponse, optionsMethod, err := mod.CreateOptions(dto.CreateOptionsInput{
-			RestApi:      input.RestApi,
-			BaseResource: baseResource,
-			Endpoint:     endpoint,
-		})
-		if err != nil {
-			return nil, err
+		resources[node.Path] = resource
+	}
+
+	var validator *apigateway.RequestValidator
+	withOptions := make(map[string]struct{}, len(input.Endpoints))
+	methods := make(map[string]string)
+	for i := 0; i < len(input.Endpoints); i++ {
+		endpoint := input.Endpoints[i]
+		path, _ := apigw_paths.Normalize(endpoint.Path)
+		baseResource := resources[path]
+
+		// Un solo OPTIONS per risorsa
+		if _, ok := withOptions[path]; !ok {
+			withOptions[path] = struct{}{}
+			integrationResponse, optionsMethod, err := mod.CreateOptions(dto.CreateOptionsInput{
+				RestApi:      input.RestApi,
+				BaseResource: baseResource,
+				Endpoint:     endpoint,
+				Cors:         opt.Coalesce(input.Cors, cors.DEFAULT),
+			})
+			if err != nil {
+				return nil, err
+			}
+			input.AllOptions = append(input.AllOptions, integrationResponse, optionsMethod)
 		}
 
-		input.AllOptions = append(input.AllOptions, integrationResponse, optionsMethod)
 		for i := 0; i < len(endpoint.Methods); i++ {
 			method := endpoint.Methods[i]
+			key := method.HttpMethod + " " + path
+			if other, ok := methods[key]; ok {
+				return nil, fmt.Errorf("duplicate method %s: declared by %s and %s", key, other, method.Name)
+			}
+			methods[key] = method.Name
+
+			params := apigw_paths.RequestParameters(path, method.QueryParameters, method.HeaderParameters)
+			var validatorId pulumi.StringPtrInput
+			if len(method.QueryParameters) != 0 || len(method.HeaderParameters) != 0 {
+				// Validator condiviso: senza, API Gateway non applica i parametri obbligatori
+				if validator == nil {
+					validator, err = apigateway.NewRequestValidator(mod.Ctx, fmt.Sprintf("%s-params-validator", input.BaseName), &apigateway.RequestValidatorArgs{
+						Name:                      pulumi.String(fmt.Sprintf("%s-params-validator", input.BaseName)),
+						RestApi:                   input.RestApi.ID(),
+						ValidateRequestParameters: pulumi.Bool(true),
+					})
+					if err != nil {
+						slog.Error("Failed to Create Request Validator", "err: ", err)
+						return nil, err
+					}
+				}
+				validatorId = validator.ID().ToStringOutput().ToStringPtrOutput()
+			}
+
+			authorizerId, authorizationType, err := methodAuthorization(method, input.AuthID, input.Authorizers)
+			if err != nil {
+				return nil, fmt.Errorf("method %s: %w", method.Name, err)
+			}
+
 			err = mod.CreateMethodIntegration(dto.CreateMethodIntegrationInput{
-				ApiID:           input.RestApi.ID(),
-				Name:            method.Name,
-				RootResourceID:  baseResource.ID(),
-				HttpMethod:      method.HttpMethod,
-				TargetLambdaArn: method.TargetLambdaInvokeArn,
-				AuthorizerId:    input.AuthID,
+				ApiID:               input.RestApi.ID(),
+				Name:                method.Name,
+				RootResourceID:      baseResource.ID(),
+				HttpMethod:          method.HttpMethod,
+				TargetLambdaArn:     method.TargetLambdaInvokeArn,
+				AuthorizerId:        authorizerId,
+				AuthorizationType:   authorizationType,
+				AuthorizationScopes: method.AuthorizationScopes,
+				RequestParameters:   pulumi.ToBoolMap(params),
+				RequestValidatorId:  validatorId,
+				ApiKeyRequired:      method.ApiKeyRequired,
 			})
 			if err != nil {
 				slog.Error("Failed to Create "+method.Name+" Method Rest API", "err: ", err)
 				return nil, err
 			}
-			_, err := lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", input.BaseName, method.Name), &lambda.PermissionArgs{
+			_, err = lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", input.BaseName, method.Name), &lambda.PermissionArgs{
 				Action:    pulumi.String("lambda:InvokeFunction"),
 				Function:  method.TargetLambdaFunctionName,
+				Qualifier: method.TargetLambdaQualifier,
 				Principal: pulumi.String("apigateway.amazonaws.com"),
-				SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/*/%s%s", input.Region, input.AccountId, input.RestApi.ID(), method.HttpMethod, baseResource.Path),
+				SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/*/%s%s", input.Region, input.AccountId, input.RestApi.ID(), method.HttpMethod, apigw_paths.SourceArnPath(path)),
 			})
 			if err != nil {
 				return nil, err
@@ -260,6 +394,20 @@ func (mod AWSModule) CreateOptions(input dto.CreateOptionsInput) (*apigateway.In
 		return nil, nil, err
 	}
 
+	// Header CORS dichiarati sulla method response e valorizzati dall'integration response
+	if len(input.Cors.AllowOrigins) == 0 {
+		input.Cors = cors.DEFAULT
+	}
+	params := input.Cors.PreflightParameters()
+	responseHeaders := pulumi.BoolMap{}
+	for name := range params {
+		responseHeaders[name] = pulumi.Bool(true)
+	}
+	var responseTemplates pulumi.StringMapInput
+	if tpl := input.Cors.PreflightTemplate(); tpl != "" {
+		responseTemplates = pulumi.StringMap{"application/json": pulumi.String(tpl)}
+	}
+
 	methodResponse, err := apigateway.NewMethodResponse(mod.Ctx, fmt.Sprintf("%s-optionsMethodResponse", input.Endpoint.Name), &apigateway.MethodResponseArgs{
 		RestApi:    input.RestApi.ID(),
 		ResourceId: input.BaseResource.ID(),
@@ -268,11 +416,7 @@ func (mod AWSModule) CreateOptions(input dto.CreateOptionsInput) (*apigateway.In
 		ResponseModels: pulumi.StringMap{
 			"application/json": pulumi.String("Empty"),
 		},
-		ResponseParameters: pulumi.BoolMap{
-			"method.response.header.Access-Control-Allow-Headers": pulumi.Bool(true),
-			"method.response.header.Access-Control-Allow-Methods": pulumi.Bool(true),
-			"method.response.header.Access-Control-Allow-Origin":  pulumi.Bool(true),
-		},
+		ResponseParameters: responseHeaders,
 	}, pulumi.DependsOn([]pulumi.Resource{optionsMethod, integration}))
 	if err != nil {
 		slog.Error("Failed to Create MethodResponse OPTIONS", "err: ", err)
@@ -280,15 +424,12 @@ func (mod AWSModule) CreateOptions(input dto.CreateOptionsInput) (*apigateway.In
 	}
 
 	integrationResponse, err := apigateway.NewIntegrationResponse(mod.Ctx, fmt.Sprintf("%s-optionsIntegrationResponse", input.Endpoint.Name), &apigateway.IntegrationResponseArgs{
-		RestApi:    input.RestApi.ID(),
-		ResourceId: input.BaseResource.ID(),
-		HttpMethod: pulumi.String("OPTIONS"),
-		StatusCode: pulumi.String("200"),
-		ResponseParameters: pulumi.StringMap{
-			"method.response.header.Access-Control-Allow-Headers": pulumi.String("'Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token'"),
-			"method.response.header.Access-Control-Allow-Methods": pulumi.String("'POST,OPTIONS,GET,PUT,PATCH,DELETE'"),
-			"method.response.header.Access-Control-Allow-Origin":  pulumi.String("'*'"),
-		},
+		RestApi:            input.RestApi.ID(),
+		ResourceId:         input.BaseResource.ID(),
+		HttpMethod:         pulumi.String("OPTIONS"),
+		StatusCode:         pulumi.String("200"),
+		ResponseParameters: pulumi.ToStringMap(params),
+		ResponseTemplates:  responseTemplates,
 	}, pulumi.DependsOn([]pulumi.Resource{optionsMethod, integration, methodResponse}))
 	if err != nil {
 		slog.Error("Failed to Create IntegrationResponse OPTIONS", "err: ", err)
@@ -299,19 +440,20 @@ func (mod AWSModule) CreateOptions(input dto.CreateOptionsInput) (*apigateway.In
 
 func (mod AWSModule) CreateMethodIntegration(input dto.CreateMethodIntegrationInput) error {
 	var methodArgs = &apigateway.MethodArgs{
-		RestApi:       input.ApiID,
-		ResourceId:    input.RootResourceID,
-		HttpMethod:    pulumi.String(input.HttpMethod),
-		Authorization: pulumi.String("NONE"),
+		RestApi:            input.ApiID,
+		ResourceId:         input.RootResourceID,
+		HttpMethod:         pulumi.String(input.HttpMethod),
+		Authorization:      pulumi.String("NONE"),
+		RequestParameters:  input.RequestParameters,
+		RequestValidatorId: input.RequestValidatorId,
+		ApiKeyRequired:     pulumi.Bool(input.ApiKeyRequired),
 	}
 	if input.AuthorizerId != nil {
-		methodArgs = &apigateway.MethodArgs{
-			RestApi:       input.ApiID,
-			ResourceId:    input.RootResourceID,
-			HttpMethod:    pulumi.String(input.HttpMethod),
-			Authorization: pulumi.String("CUSTOM"),
-			AuthorizerId:  *input.AuthorizerId,
-		}
+		methodArgs.Authorization = pulumi.String(orDefault(input.AuthorizationType, "CUSTOM"))
+		methodArgs.AuthorizerId = *input.AuthorizerId
+	}
+	if len(input.AuthorizationScopes) != 0 {
+		methodArgs.AuthorizationScopes = pulumi.ToStringArray(input.AuthorizationScopes)
 	}
 	createdMethod, err := apigateway.NewMethod(mod.Ctx, fmt.Sprintf("%s-method", input.Name), methodArgs)
 	if err != nil {
@@ -344,18 +486,8 @@ func (mod AWSModule) CreateStage(input dto.CreateStageInput) (*apigateway.Stage,
 		return nil, err
 	}
 
-	_, err = apigateway.NewMethodSettings(mod.Ctx, "methodSettings", &apigateway.MethodSettingsArgs{
-		RestApi:   input.RestApi.ID(),
-		StageName: stage.StageName,
-		Settings: apigateway.MethodSettingsSettingsArgs{
-			LoggingLevel:     pulumi.String("INFO"),
-			MetricsEnabled:   pulumi.Bool(true),
-			DataTraceEnabled: pulumi.Bool(true),
-		},
-		MethodPath: pulumi.String("*/*"),
-	})
+	err = mod.createMethodSettings(input.BaseName, "methodSettings", input.RestApi, stage, input.Throttle, input.MethodSettings)
 	if err != nil {
-		slog.Error("Failed to Create API Stage Method settings", "err", err)
 		return nil, err
 	}
 
@@ -363,10 +495,84 @@ func (mod AWSModule) CreateStage(input dto.CreateStageInput) (*apigateway.Stage,
 }
 
 func (mod AWSModule) CreateAuthorizer(input dto.CreateAuthorizerInput) (*apigateway.Authorizer, error) {
-	invokeRole, err := iam.NewRole(mod.Ctx, fmt.Sprintf("%s-invocation-role", input.BaseName), &iam.RoleArgs{
-		Name:             pulumi.String(fmt.Sprintf("%s-invocation-role", input.BaseName)),
+	baseName := input.BaseName
+	if input.Name != "" {
+		baseName = fmt.Sprintf("%s-%s", input.BaseName, input.Name)
+	}
+	authType := input.Type
+	if authType == "" {
+		authType = dto.AuthorizerTypeToken
+	}
+
+	authArgs := &apigateway.AuthorizerArgs{
+		Name:                         pulumi.StringPtr(fmt.Sprintf("%s-authorizer", baseName)),
+		RestApi:                      input.RestApi.ID(),
+		AuthorizerResultTtlInSeconds: pulumi.Int(input.TtlInSeconds),
+		Type:                         pulumi.String(string(authType)),
+	}
+	switch authType {
+	case dto.AuthorizerTypeCognito:
+		if input.ProviderArns == nil {
+			return nil, fmt.Errorf("authorizer %s: ProviderArns is required", baseName)
+		}
+		authArgs.ProviderArns = input.ProviderArns
+		authArgs.IdentitySource = pulumi.String(fmt.Sprintf("method.request.header.%s", orDefault(input.IdentitySource, "Authorization")))
+	case dto.AuthorizerTypeToken:
+		authArgs.IdentitySource = pulumi.String(fmt.Sprintf("method.request.header.%s", orDefault(input.IdentitySource, "Authorization")))
+	case dto.AuthorizerTypeRequest:
+		// Con la cache attiva API Gateway richiede almeno una identity source
+		if input.TtlInSeconds > 0 && len(input.IdentitySources) == 0 {
+			return nil, fmt.Errorf("authorizer %s: IdentitySources is required with TtlInSeconds", baseName)
+		}
+		if len(input.IdentitySources) != 0 {
+			authArgs.IdentitySource = pulumi.String(strings.Join(input.IdentitySources, ","))
+		}
+	default:
+		return nil, fmt.Errorf("authorizer %s: unsupported type %s", baseName, authType)
+	}
+
+	// Lambda authorizer: ruolo di invocazione e permesso
+	if authType != dto.AuthorizerTypeCognito {
+		if input.LambdaAuth == nil {
+			return nil, fmt.Errorf("authorizer %s: LambdaAuth is required", baseName)
+		}
+		invokeRole, err := mod.createAuthorizerInvokeRole(baseName, input.Tags, input.LambdaAuth)
+		if err != nil {
+			return nil, err
+		}
+		authArgs.AuthorizerUri = input.LambdaAuth.InvokeArn
+		authArgs.AuthorizerCredentials = invokeRole.Arn
+	}
+
+	authorizer, err := apigateway.NewAuthorizer(mod.Ctx, fmt.Sprintf("%s-authorizer", baseName), authArgs)
+	if err != nil {
+		slog.Error("Failed to Create API Authorizer", "err: ", err)
+		return nil, err
+	}
+
+	if authType == dto.AuthorizerTypeCognito {
+		return authorizer, nil
+	}
+
+	_, err = lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", baseName, "authorizer"), &lambda.PermissionArgs{
+		Action:    pulumi.String("lambda:InvokeFunction"),
+		Function:  input.LambdaAuth.Name,
+		Principal: pulumi.String("apigateway.amazonaws.com"),
+		SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/authorizers/%s", input.Region, input.AccountId, input.RestApi.ID(), authorizer.ID().ToStringOutput()),
+	})
+	if err != nil {
+		slog.Error("Failed to Create Authorizer apiGW permission", "err: ", err)
+		return nil, err
+	}
+	return authorizer, nil
+}
+
+// createAuthorizerInvokeRole creates the role API Gateway assumes to invoke the authorizer Lambda
+func (mod AWSModule) createAuthorizerInvokeRole(baseName string, tags pulumi.StringMapInput, lambdaAuth *lambda.Function) (*iam.Role, error) {
+	invokeRole, err := iam.NewRole(mod.Ctx, fmt.Sprintf("%s-invocation-role", baseName), &iam.RoleArgs{
+		Name:             pulumi.String(fmt.Sprintf("%s-invocation-role", baseName)),
 		Path:             pulumi.String("/"),
-		Tags:             input.Tags,
+		Tags:             tags,
 		AssumeRolePolicy: pulumi.String(policy.IAM_APIGW_ASSUME_ROLE),
 	})
 	if err != nil {
@@ -374,9 +580,9 @@ func (mod AWSModule) CreateAuthorizer(input dto.CreateAuthorizerInput) (*apigate
 		return nil, err
 	}
 
-	_, err = iam.NewRolePolicy(mod.Ctx, fmt.Sprintf("%s-invoke-policy", input.BaseName), &iam.RolePolicyArgs{
+	_, err = iam.NewRolePolicy(mod.Ctx, fmt.Sprintf("%s-invoke-policy", baseName), &iam.RolePolicyArgs{
 		Role: invokeRole.ID(),
-		Policy: pulumi.All(input.LambdaAuth.Arn).ApplyT(func(all []any) (string, error) {
+		Policy: pulumi.All(lambdaAuth.Arn).ApplyT(func(all []any) (string, error) {
 			lambdaArn := all[0].(string)
 			doc := fmt.Sprintf(string(policy.IAM_LAMBDA_INVOKE_ROLE), lambdaArn)
 			return doc, nil
@@ -387,31 +593,30 @@ func (mod AWSModule) CreateAuthorizer(input dto.CreateAuthorizerInput) (*apigate
 		return nil, err
 	}
 
-	authorizer, err := apigateway.NewAuthorizer(mod.Ctx, fmt.Sprintf("%s-authorizer", input.BaseName), &apigateway.AuthorizerArgs{
-		Name:                         pulumi.StringPtr(fmt.Sprintf("%s-authorizer", input.BaseName)),
-		RestApi:                      input.RestApi.ID(),
-		AuthorizerUri:                input.LambdaAuth.InvokeArn,
-		AuthorizerCredentials:        invokeRole.Arn,
-		AuthorizerResultTtlInSeconds: pulumi.Int(0),
-		IdentitySource:               pulumi.String(fmt.Sprintf("method.request.header.%s", input.IdentitySource)),
-		Type:                         pulumi.String("TOKEN"),
-	})
-	if err != nil {
-		slog.Error("Failed to Create API Authorizer", "err: ", err)
-		return nil, err
+	return invokeRole, nil
+}
+
+// methodAuthorization resolves the authorizer of the method: by name, the fallback AuthID or none
+func methodAuthorization(method dto.Methods, authID *pulumi.StringPtrInput, authorizers map[string]dto.ApiAuthorizer) (*pulumi.StringPtrInput, string, error) {
+	auth, ok := authorizers[method.Authorizer]
+	if len(method.AuthorizationScopes) != 0 && (!ok || auth.Type != dto.AuthorizerTypeCognito) {
+		return nil, "", errors.New("AuthorizationScopes requires a COGNITO_USER_POOLS authorizer")
 	}
 
-	_, err = lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", input.BaseName, "authorizer"), &lambda.PermissionArgs{
-		Action:    pulumi.String("lambda:InvokeFunction"),
-		Function:  input.LambdaAuth.Name,
-		Principal: pulumi.String("apigateway.amazonaws.com"),
-		SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/authorizers/%s", input.Region, input.AccountId, input.RestApi.ID(), authorizer.ID().ToStringOutput()),
-	})
-	if err != nil {
-		slog.Error("Failed to Create Authorizer apiGW permission", "err: ", err)
-		return nil, err
+	switch {
+	case method.Authorizer == dto.NO_AUTHORIZER:
+		return nil, "", nil
+	case method.Authorizer == "":
+		return authID, "", nil
+	case !ok:
+		return nil, "", fmt.Errorf("unknown authorizer %s", method.Authorizer)
 	}
-	return authorizer, nil
+
+	var id pulumi.StringPtrInput = auth.Authorizer.ID().ToStringOutput().ToStringPtrOutput()
+	if auth.Type == dto.AuthorizerTypeCognito {
+		return &id, string(dto.AuthorizerTypeCognito), nil
+	}
+	return &id, "CUSTOM", nil
 }
 
 func validateUniqueEndpoints(endpoints []dto.Endpoints) error {
@@ -0,0 +1,147 @@
+package vtech_aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/ptr"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	apigw_openapi "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/apigw/openapi"
+	apigw_paths "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/apigw/paths"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/apigateway"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createOpenAPIRestApi creates the RestApi from the OpenAPI document, injecting the Lambda
+// integrations, and returns the Deployment triggers bound to the rendered body
+func (mod AWSModule) createOpenAPIRestApi(input dto.CreateRestAPIInput) (*apigateway.RestApi, pulumi.StringMap, error) {
+	operations, err := apigw_openapi.Operations(input.OpenAPI.Document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := slices.Sorted(maps.Keys(input.OpenAPI.Integrations))
+	authorized := false
+	inputs := make([]any, 0, len(keys)+2)
+	for _, key := range keys {
+		integration := input.OpenAPI.Integrations[key]
+		if integration.TargetLambdaInvokeArn == nil || integration.TargetLambdaFunctionName == nil {
+			return nil, nil, fmt.Errorf("openapi integration %s: TargetLambdaInvokeArn and TargetLambdaFunctionName are required", key)
+		}
+		authorized = authorized || integration.Authorized
+		inputs = append(inputs, integration.TargetLambdaInvokeArn)
+	}
+
+	// Authorizer: solo se qualche operazione lo richiede
+	var authorizer *apigw_openapi.Authorizer
+	if authorized {
+		if input.LambdaAuth == nil {
+			return nil, nil, fmt.Errorf("openapi %s: LambdaAuth is required by the authorized integrations", input.BaseName)
+		}
+		invokeRole, err := mod.createAuthorizerInvokeRole(input.BaseName, input.Tags, input.LambdaAuth)
+		if err != nil {
+			return nil, nil, err
+		}
+		inputs = append(inputs, input.LambdaAuth.InvokeArn, invokeRole.Arn)
+		authorizer = &apigw_openapi.Authorizer{
+			Name:       fmt.Sprintf("%s-authorizer", input.BaseName),
+			HeaderName: orDefault(input.IdentitySource, "Authorization"),
+		}
+	}
+
+	var preflight *cors.Config
+	if input.OpenAPI.Cors {
+		preflight = ptr.Const(opt.Coalesce(input.Cors, cors.DEFAULT))
+	}
+
+	document := input.OpenAPI.Document
+	body := pulumi.All(inputs...).ApplyT(func(all []any) (string, error) {
+		opts := apigw_openapi.Options{
+			Integrations: make(map[string]apigw_openapi.Integration, len(keys)),
+			Cors:         preflight,
+		}
+		for i, key := range keys {
+			opts.Integrations[key] = apigw_openapi.Integration{
+				Uri:            all[i].(string),
+				Authorized:     input.OpenAPI.Integrations[key].Authorized,
+				ApiKeyRequired: input.OpenAPI.Integrations[key].ApiKeyRequired,
+			}
+		}
+		if authorizer != nil {
+			auth := *authorizer
+			auth.Uri = all[len(keys)].(string)
+			auth.Credentials = all[len(keys)+1].(string)
+			opts.Authorizer = &auth
+		}
+		return apigw_openapi.Render(document, opts)
+	}).(pulumi.StringOutput)
+
+	restApi, err := apigateway.NewRestApi(mod.Ctx, fmt.Sprintf("%s-rest-api", input.BaseName), &apigateway.RestApiArgs{
+		Name: pulumi.StringPtr(fmt.Sprintf("%s-rest-api", input.BaseName)),
+		Body: body,
+		Tags: input.Tags,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Permessi di invocazione per operazione
+	for _, op := range operations {
+		integration, ok := input.OpenAPI.Integrations[op.Key]
+		if !ok {
+			return nil, nil, fmt.Errorf("openapi %s: operation %q has no integration", input.BaseName, op.Key)
+		}
+		_, err := lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", input.BaseName, permissionName(op)), &lambda.PermissionArgs{
+			Action:    pulumi.String("lambda:InvokeFunction"),
+			Function:  integration.TargetLambdaFunctionName,
+			Qualifier: integration.TargetLambdaQualifier,
+			Principal: pulumi.String("apigateway.amazonaws.com"),
+			SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/*/%s%s", input.Region, input.AccountId, restApi.ID(), op.Method, apigw_paths.SourceArnPath(op.Path)),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if authorizer != nil {
+		_, err = lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-%s-lambda-permission", input.BaseName, "authorizer"), &lambda.PermissionArgs{
+			Action:    pulumi.String("lambda:InvokeFunction"),
+			Function:  input.LambdaAuth.Name,
+			Principal: pulumi.String("apigateway.amazonaws.com"),
+			SourceArn: pulumi.Sprintf("arn:aws:execute-api:%s:%s:%s/authorizers/*", input.Region, input.AccountId, restApi.ID()),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Nuovo deploy ad ogni modifica del Body
+	triggers := pulumi.StringMap{
+		"redeployment": body.ApplyT(func(b string) string {
+			sum := sha256.Sum256([]byte(b))
+			return hex.EncodeToString(sum[:])
+		}).(pulumi.StringOutput),
+	}
+
+	return restApi, triggers, nil
+}
+
+// permissionName derives a resource-safe name from the operation key
+func permissionName(op apigw_openapi.Operation) string {
+	out := make([]rune, 0, len(op.Key))
+	for _, r := range op.Key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			out = append(out, r)
+		default:
+			out = append(out, '-')
+		}
+	}
+	return string(out)
+}
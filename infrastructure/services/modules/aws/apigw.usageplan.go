@@ -0,0 +1,158 @@
+package vtech_aws
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	apigw_paths "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/apigw/paths"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/apigateway"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CreateUsagePlan creates the usage plan of the stage with its API keys
+func (mod AWSModule) CreateUsagePlan(input dto.CreateUsagePlanInput) (*dto.UsagePlanOutput, error) {
+	if input.Name == "" {
+		return nil, errors.New("usage plan: Name is required")
+	}
+	name := fmt.Sprintf("%s-%s", input.BaseName, input.Name)
+
+	throttles := apigateway.UsagePlanApiStageThrottleArray{}
+	seen := make(map[string]struct{}, len(input.MethodThrottles))
+	for _, m := range input.MethodThrottles {
+		if m.Throttle == nil {
+			return nil, fmt.Errorf("usage plan %s: method %s %s has no Throttle", input.Name, m.HttpMethod, m.Path)
+		}
+		throttlePath := apigw_paths.ThrottlePath(m.Path, m.HttpMethod)
+		if _, ok := seen[throttlePath]; ok {
+			return nil, fmt.Errorf("usage plan %s: duplicate method throttle %s", input.Name, throttlePath)
+		}
+		seen[throttlePath] = struct{}{}
+
+		throttles = append(throttles, &apigateway.UsagePlanApiStageThrottleArgs{
+			Path:       pulumi.String(throttlePath),
+			RateLimit:  pulumi.Float64(m.Throttle.RateLimit),
+			BurstLimit: pulumi.Int(m.Throttle.BurstLimit),
+		})
+	}
+
+	planArgs := &apigateway.UsagePlanArgs{
+		Name: pulumi.String(name),
+		ApiStages: apigateway.UsagePlanApiStageArray{
+			&apigateway.UsagePlanApiStageArgs{
+				ApiId:     input.RestApi.ID(),
+				Stage:     input.Stage.StageName,
+				Throttles: throttles,
+			},
+		},
+		Tags: input.Tags,
+	}
+	if input.Description != "" {
+		planArgs.Description = pulumi.String(input.Description)
+	}
+	if input.Throttle != nil {
+		planArgs.ThrottleSettings = &apigateway.UsagePlanThrottleSettingsArgs{
+			RateLimit:  pulumi.Float64(input.Throttle.RateLimit),
+			BurstLimit: pulumi.Int(input.Throttle.BurstLimit),
+		}
+	}
+	if input.Quota != nil {
+		planArgs.QuotaSettings = &apigateway.UsagePlanQuotaSettingsArgs{
+			Limit:  pulumi.Int(input.Quota.Limit),
+			Period: pulumi.String(orDefault(input.Quota.Period, "MONTH")),
+			Offset: pulumi.Int(input.Quota.Offset),
+		}
+	}
+
+	plan, err := apigateway.NewUsagePlan(mod.Ctx, fmt.Sprintf("%s-usage-plan", name), planArgs)
+	if err != nil {
+		slog.Error("Failed to Create Usage Plan", "err: ", err)
+		return nil, err
+	}
+
+	out := &dto.UsagePlanOutput{
+		UsagePlan: plan,
+		ApiKeys:   make(map[string]*apigateway.ApiKey, len(input.ApiKeys)),
+	}
+	for _, key := range input.ApiKeys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("usage plan %s: api key Name is required", input.Name)
+		}
+		if _, ok := out.ApiKeys[key.Name]; ok {
+			return nil, fmt.Errorf("usage plan %s: duplicate api key %s", input.Name, key.Name)
+		}
+
+		keyArgs := &apigateway.ApiKeyArgs{
+			Name:    pulumi.String(fmt.Sprintf("%s-%s", name, key.Name)),
+			Enabled: pulumi.Bool(true),
+			Value:   key.Value,
+			Tags:    input.Tags,
+		}
+		if key.Description != "" {
+			keyArgs.Description = pulumi.String(key.Description)
+		}
+		apiKey, err := apigateway.NewApiKey(mod.Ctx, fmt.Sprintf("%s-%s-api-key", name, key.Name), keyArgs)
+		if err != nil {
+			slog.Error("Failed to Create API Key", "err: ", err)
+			return nil, err
+		}
+
+		_, err = apigateway.NewUsagePlanKey(mod.Ctx, fmt.Sprintf("%s-%s-usage-plan-key", name, key.Name), &apigateway.UsagePlanKeyArgs{
+			KeyId:       apiKey.ID(),
+			KeyType:     pulumi.String("API_KEY"),
+			UsagePlanId: plan.ID(),
+		})
+		if err != nil {
+			slog.Error("Failed to Create Usage Plan Key", "err: ", err)
+			return nil, err
+		}
+		out.ApiKeys[key.Name] = apiKey
+	}
+
+	return out, nil
+}
+
+// createMethodSettings creates the stage settings ("*/*", resource named defaultName) and the per method overrides
+func (mod AWSModule) createMethodSettings(baseName string, defaultName string, restApi *apigateway.RestApi, stage *apigateway.Stage, throttle *dto.ApiThrottle, overrides []dto.ApiMethodSetting) error {
+	settings := []dto.ApiMethodSetting{{Throttle: throttle}}
+	settings = append(settings, overrides...)
+
+	seen := make(map[string]struct{}, len(settings))
+	for i, s := range settings {
+		methodPath := apigw_paths.MethodPath(s.Path, s.HttpMethod)
+		if _, ok := seen[methodPath]; ok {
+			return fmt.Errorf("method settings %s: duplicate %s", baseName, methodPath)
+		}
+		seen[methodPath] = struct{}{}
+
+		// Method settings replace the stage ones: logging and metrics fall back to the defaults,
+		// the throttle to the stage one
+		args := apigateway.MethodSettingsSettingsArgs{
+			LoggingLevel:     pulumi.String(opt.Coalesce(s.LoggingLevel, "INFO")),
+			MetricsEnabled:   pulumi.Bool(opt.Coalesce(s.MetricsEnabled, true)),
+			DataTraceEnabled: pulumi.Bool(opt.Coalesce(s.DataTraceEnabled, true)),
+		}
+		if t := opt.FirstNonNil(s.Throttle, throttle); t != nil {
+			args.ThrottlingRateLimit = pulumi.Float64(t.RateLimit)
+			args.ThrottlingBurstLimit = pulumi.Int(t.BurstLimit)
+		}
+
+		name := defaultName
+		if i > 0 {
+			name = fmt.Sprintf("%s-methodSettings-%s", baseName, methodPath)
+		}
+		_, err := apigateway.NewMethodSettings(mod.Ctx, name, &apigateway.MethodSettingsArgs{
+			RestApi:    restApi.ID(),
+			StageName:  stage.StageName,
+			Settings:   args,
+			MethodPath: pulumi.String(methodPath),
+		})
+		if err != nil {
+			slog.Error("Failed to Create API Stage Method settings", "err", err)
+			return err
+		}
+	}
+	return nil
+}
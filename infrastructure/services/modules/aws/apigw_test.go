@@ -0,0 +1,74 @@
+package vtech_aws
+
+import (
+	"testing"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/apigateway"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type mocks struct{}
+
+func (mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func TestMethodAuthorization(t *testing.T) {
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		newAuthorizer := func(name string, authType dto.AuthorizerType) dto.ApiAuthorizer {
+			a, err := apigateway.NewAuthorizer(ctx, name, &apigateway.AuthorizerArgs{
+				RestApi: pulumi.String("api"),
+				Type:    pulumi.String(string(authType)),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return dto.ApiAuthorizer{Authorizer: a, Type: authType}
+		}
+		authorizers := map[string]dto.ApiAuthorizer{
+			"users":  newAuthorizer("users", dto.AuthorizerTypeCognito),
+			"tokens": newAuthorizer("tokens", dto.AuthorizerTypeToken),
+		}
+		var fallback pulumi.StringPtrInput = pulumi.StringPtr("fallback")
+
+		tests := []struct {
+			name     string
+			method   dto.Methods
+			wantID   bool
+			wantType string
+			wantErr  bool
+		}{
+			{name: "fallback", method: dto.Methods{}, wantID: true},
+			{name: "none", method: dto.Methods{Authorizer: dto.NO_AUTHORIZER}},
+			{name: "cognito", method: dto.Methods{Authorizer: "users", AuthorizationScopes: []string{"orders/read"}}, wantID: true, wantType: "COGNITO_USER_POOLS"},
+			{name: "lambda", method: dto.Methods{Authorizer: "tokens"}, wantID: true, wantType: "CUSTOM"},
+			{name: "unknown", method: dto.Methods{Authorizer: "missing"}, wantErr: true},
+			{name: "scopes without cognito", method: dto.Methods{Authorizer: "tokens", AuthorizationScopes: []string{"orders/read"}}, wantErr: true},
+			{name: "scopes on fallback", method: dto.Methods{AuthorizationScopes: []string{"orders/read"}}, wantErr: true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				id, authType, err := methodAuthorization(tt.method, &fallback, authorizers)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("methodAuthorization() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if (id != nil) != tt.wantID {
+					t.Errorf("methodAuthorization() id = %v, wantID %v", id, tt.wantID)
+				}
+				if authType != tt.wantType {
+					t.Errorf("methodAuthorization() type = %q, want %q", authType, tt.wantType)
+				}
+			})
+		}
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,247 @@
+package vtech_aws
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/appautoscaling"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createServiceAutoscaling registers the service DesiredCount as scalable target and creates
+// the target tracking (CPU, Memory, ALB requests, custom metrics), step and scheduled policies
+func (mod AWSModule) createServiceAutoscaling(
+	ctx *pulumi.Context,
+	baseName string,
+	clusterName pulumi.StringOutput,
+	svc *ecs.Service,
+	in dto.ECSInput,
+	lbRes *ecsLoadBalancerResources,
+) (*appautoscaling.Target, error) {
+	as := in.Autoscaling
+	if as.RequestCountPerTarget > 0 && (lbRes == nil || lbRes.LbType != "application") {
+		return nil, errors.New("ecs autoscaling: RequestCountPerTarget requires an application load balancer")
+	}
+	if err := validateAutoscalingNames(as); err != nil {
+		return nil, err
+	}
+
+	resID := pulumi.Sprintf("service/%s/%s", clusterName, svc.Name)
+
+	asgTarget, err := appautoscaling.NewTarget(ctx, baseName+"-as-target", &appautoscaling.TargetArgs{
+		MaxCapacity:       pulumi.Int(in.MaxCapacity),
+		MinCapacity:       pulumi.Int(in.MinCapacity),
+		ResourceId:        resID,
+		ScalableDimension: pulumi.String("ecs:service:DesiredCount"),
+		ServiceNamespace:  pulumi.String("ecs"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Target tracking
+	targetTracking := func(name string, target float64, predefined *appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs, custom *appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationCustomizedMetricSpecificationArgs) error {
+		cfg := &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationArgs{
+			PredefinedMetricSpecification: predefined,
+			CustomizedMetricSpecification: custom,
+			TargetValue:                   pulumi.Float64(target),
+		}
+		if as.DisableScaleIn {
+			cfg.DisableScaleIn = pulumi.Bool(true)
+		}
+		if as.ScaleInCooldown > 0 {
+			cfg.ScaleInCooldown = pulumi.Int(as.ScaleInCooldown)
+		}
+		if as.ScaleOutCooldown > 0 {
+			cfg.ScaleOutCooldown = pulumi.Int(as.ScaleOutCooldown)
+		}
+
+		_, err := appautoscaling.NewPolicy(ctx, name, &appautoscaling.PolicyArgs{
+			PolicyType:                               pulumi.String("TargetTrackingScaling"),
+			ResourceId:                               asgTarget.ResourceId,
+			ScalableDimension:                        asgTarget.ScalableDimension,
+			ServiceNamespace:                         asgTarget.ServiceNamespace,
+			TargetTrackingScalingPolicyConfiguration: cfg,
+		})
+		return err
+	}
+
+	if !as.DisableMemory {
+		if err := targetTracking(baseName+"-as-mem", in.TargetMemory, &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
+			PredefinedMetricType: pulumi.String("ECSServiceAverageMemoryUtilization"),
+		}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if !as.DisableCPU {
+		if err := targetTracking(baseName+"-as-cpu", in.TargetCPU, &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
+			PredefinedMetricType: pulumi.String("ECSServiceAverageCPUUtilization"),
+		}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if as.RequestCountPerTarget > 0 {
+		if err := targetTracking(baseName+"-as-req", as.RequestCountPerTarget, &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
+			PredefinedMetricType: pulumi.String("ALBRequestCountPerTarget"),
+			ResourceLabel:        pulumi.Sprintf("%s/%s", lbRes.ArnSuffix, lbRes.TargetGroup.ArnSuffix),
+		}, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range as.CustomMetrics {
+		var dimensions appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationCustomizedMetricSpecificationDimensionArray
+		for _, k := range slices.Sorted(maps.Keys(m.Dimensions)) {
+			dimensions = append(dimensions, &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationCustomizedMetricSpecificationDimensionArgs{
+				Name:  pulumi.String(k),
+				Value: pulumi.String(m.Dimensions[k]),
+			})
+		}
+		custom := &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationCustomizedMetricSpecificationArgs{
+			Namespace:  pulumi.String(m.Namespace),
+			MetricName: pulumi.String(m.MetricName),
+			Statistic:  pulumi.String(orDefault(m.Statistic, "Average")),
+			Dimensions: dimensions,
+		}
+		if m.Unit != "" {
+			custom.Unit = pulumi.String(m.Unit)
+		}
+		if err := targetTracking(fmt.Sprintf("%s-as-%s", baseName, m.Name), m.TargetValue, nil, custom); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step scaling + allarme CloudWatch
+	for _, step := range as.StepScaling {
+		var adjustments appautoscaling.PolicyStepScalingPolicyConfigurationStepAdjustmentArray
+		for _, s := range step.Steps {
+			adj := &appautoscaling.PolicyStepScalingPolicyConfigurationStepAdjustmentArgs{
+				ScalingAdjustment: pulumi.Int(s.Adjustment),
+			}
+			if s.LowerBound != nil {
+				adj.MetricIntervalLowerBound = pulumi.String(strconv.FormatFloat(*s.LowerBound, 'f', -1, 64))
+			}
+			if s.UpperBound != nil {
+				adj.MetricIntervalUpperBound = pulumi.String(strconv.FormatFloat(*s.UpperBound, 'f', -1, 64))
+			}
+			adjustments = append(adjustments, adj)
+		}
+
+		stepCfg := &appautoscaling.PolicyStepScalingPolicyConfigurationArgs{
+			AdjustmentType:        pulumi.String(orDefault(step.AdjustmentType, "ChangeInCapacity")),
+			MetricAggregationType: pulumi.String(orDefault(step.MetricAggregationType, "Average")),
+			StepAdjustments:       adjustments,
+		}
+		if step.Cooldown > 0 {
+			stepCfg.Cooldown = pulumi.Int(step.Cooldown)
+		}
+
+		stepPolicy, err := appautoscaling.NewPolicy(ctx, fmt.Sprintf("%s-as-%s", baseName, step.Name), &appautoscaling.PolicyArgs{
+			PolicyType:                     pulumi.String("StepScaling"),
+			ResourceId:                     asgTarget.ResourceId,
+			ScalableDimension:              asgTarget.ScalableDimension,
+			ServiceNamespace:               asgTarget.ServiceNamespace,
+			StepScalingPolicyConfiguration: stepCfg,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		evaluationPeriods := 1
+		if step.Alarm.EvaluationPeriods > 0 {
+			evaluationPeriods = step.Alarm.EvaluationPeriods
+		}
+		period := 60
+		if step.Alarm.Period > 0 {
+			period = step.Alarm.Period
+		}
+		if _, err := cloudwatch.NewMetricAlarm(ctx, fmt.Sprintf("%s-as-%s-alarm", baseName, step.Name), &cloudwatch.MetricAlarmArgs{
+			Name:               pulumi.String(fmt.Sprintf("%s-%s", baseName, step.Name)),
+			Namespace:          pulumi.String(step.Alarm.Namespace),
+			MetricName:         pulumi.String(step.Alarm.MetricName),
+			Statistic:          pulumi.String(orDefault(step.Alarm.Statistic, "Average")),
+			Dimensions:         pulumi.ToStringMap(step.Alarm.Dimensions),
+			ComparisonOperator: pulumi.String(step.Alarm.ComparisonOperator),
+			Threshold:          pulumi.Float64(step.Alarm.Threshold),
+			EvaluationPeriods:  pulumi.Int(evaluationPeriods),
+			Period:             pulumi.Int(period),
+			AlarmActions:       pulumi.Array{stepPolicy.Arn},
+			Tags:               mod.DefaultTags,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Scheduled scaling
+	for _, sched := range as.Scheduled {
+		action := &appautoscaling.ScheduledActionScalableTargetActionArgs{
+			MinCapacity: pulumi.IntPtrFromPtr(sched.MinCapacity),
+			MaxCapacity: pulumi.IntPtrFromPtr(sched.MaxCapacity),
+		}
+
+		args := &appautoscaling.ScheduledActionArgs{
+			Name:                 pulumi.String(fmt.Sprintf("%s-%s", baseName, sched.Name)),
+			ResourceId:           asgTarget.ResourceId,
+			ScalableDimension:    asgTarget.ScalableDimension,
+			ServiceNamespace:     asgTarget.ServiceNamespace,
+			Schedule:             pulumi.String(sched.Schedule),
+			ScalableTargetAction: action,
+		}
+		if sched.Timezone != "" {
+			args.Timezone = pulumi.String(sched.Timezone)
+		}
+		if _, err := appautoscaling.NewScheduledAction(ctx, fmt.Sprintf("%s-as-%s", baseName, sched.Name), args); err != nil {
+			return nil, err
+		}
+	}
+
+	return asgTarget, nil
+}
+
+// validateAutoscalingNames checks that the custom, step and scheduled policy names, used in the
+// "<baseName>-as-<name>" resource names, are set, unique and not used by the built-in resources
+func validateAutoscalingNames(as dto.ECSAutoscaling) error {
+	seen := map[string]struct{}{"target": {}, "mem": {}, "cpu": {}, "req": {}}
+	add := func(kind string, name string, suffixes ...string) error {
+		if name == "" {
+			return fmt.Errorf("ecs autoscaling: %s policy Name is required", kind)
+		}
+		for _, n := range append([]string{name}, suffixes...) {
+			if _, ok := seen[n]; ok {
+				return fmt.Errorf("ecs autoscaling: %s policy name %q is reserved or already used", kind, name)
+			}
+			seen[n] = struct{}{}
+		}
+		return nil
+	}
+
+	for _, m := range as.CustomMetrics {
+		if err := add("custom metric", m.Name); err != nil {
+			return err
+		}
+	}
+	for _, step := range as.StepScaling {
+		// Lo step crea anche l'allarme "<name>-alarm"
+		if err := add("step scaling", step.Name, step.Name+"-alarm"); err != nil {
+			return err
+		}
+	}
+	for _, sched := range as.Scheduled {
+		if err := add("scheduled", sched.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func orDefault(val, def string) string {
+	if val != "" {
+		return val
+	}
+	return def
+}
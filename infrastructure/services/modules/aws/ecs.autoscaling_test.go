@@ -0,0 +1,56 @@
+package vtech_aws
+
+import (
+	"testing"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+)
+
+func TestValidateAutoscalingNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		as      dto.ECSAutoscaling
+		wantErr bool
+	}{
+		{name: "no policies", as: dto.ECSAutoscaling{}},
+		{
+			name: "unique names",
+			as: dto.ECSAutoscaling{
+				CustomMetrics: []dto.ECSCustomMetricScaling{{Name: "queue"}},
+				StepScaling:   []dto.ECSStepScaling{{Name: "burst"}},
+				Scheduled:     []dto.ECSScheduledScaling{{Name: "night"}, {Name: "morning"}},
+			},
+		},
+		{name: "empty custom metric", as: dto.ECSAutoscaling{CustomMetrics: []dto.ECSCustomMetricScaling{{}}}, wantErr: true},
+		{name: "empty step", as: dto.ECSAutoscaling{StepScaling: []dto.ECSStepScaling{{}}}, wantErr: true},
+		{name: "empty scheduled", as: dto.ECSAutoscaling{Scheduled: []dto.ECSScheduledScaling{{}}}, wantErr: true},
+		{name: "reserved cpu", as: dto.ECSAutoscaling{CustomMetrics: []dto.ECSCustomMetricScaling{{Name: "cpu"}}}, wantErr: true},
+		{name: "reserved mem", as: dto.ECSAutoscaling{Scheduled: []dto.ECSScheduledScaling{{Name: "mem"}}}, wantErr: true},
+		{name: "reserved req", as: dto.ECSAutoscaling{StepScaling: []dto.ECSStepScaling{{Name: "req"}}}, wantErr: true},
+		{name: "reserved target", as: dto.ECSAutoscaling{Scheduled: []dto.ECSScheduledScaling{{Name: "target"}}}, wantErr: true},
+		{
+			name: "duplicate across kinds",
+			as: dto.ECSAutoscaling{
+				CustomMetrics: []dto.ECSCustomMetricScaling{{Name: "queue"}},
+				Scheduled:     []dto.ECSScheduledScaling{{Name: "queue"}},
+			},
+			wantErr: true,
+		},
+		{
+			// Lo step "burst" crea anche l'allarme "burst-alarm"
+			name: "step alarm collision",
+			as: dto.ECSAutoscaling{
+				StepScaling: []dto.ECSStepScaling{{Name: "burst"}},
+				Scheduled:   []dto.ECSScheduledScaling{{Name: "burst-alarm"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAutoscalingNames(tt.as); (err != nil) != tt.wantErr {
+				t.Errorf("validateAutoscalingNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
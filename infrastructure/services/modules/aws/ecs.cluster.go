@@ -0,0 +1,97 @@
+package vtech_aws
+
+import (
+	"slices"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CreateCluster creates an ECS cluster meant to be shared by several services
+// (pass the output as ECSInput.Cluster), with Container Insights and
+// FARGATE / FARGATE_SPOT capacity providers
+func (mod AWSModule) CreateCluster(ctx *pulumi.Context, in dto.ECSClusterInput) (*dto.ECSClusterOutput, error) {
+	if in.ContainerInsights == "" {
+		in.ContainerInsights = "enabled"
+	}
+	if len(in.CapacityProviders) == 0 {
+		in.CapacityProviders = []string{"FARGATE", "FARGATE_SPOT"}
+	}
+
+	out, _, err := mod.createCluster(ctx, in.Name, in)
+	return out, err
+}
+
+// createCluster creates the cluster and, if needed, its capacity providers association.
+// The returned resources must be awaited by services using capacity provider strategies
+func (mod AWSModule) createCluster(ctx *pulumi.Context, resourceName string, in dto.ECSClusterInput) (*dto.ECSClusterOutput, []pulumi.Resource, error) {
+	args := &ecs.ClusterArgs{
+		Name: pulumi.String(in.Name),
+		Tags: mod.DefaultTags,
+	}
+	if in.ContainerInsights != "" {
+		args.Settings = ecs.ClusterSettingArray{
+			&ecs.ClusterSettingArgs{
+				Name:  pulumi.String("containerInsights"),
+				Value: pulumi.String(in.ContainerInsights),
+			},
+		}
+	}
+
+	cluster, err := ecs.NewCluster(ctx, resourceName, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	deps := []pulumi.Resource{cluster}
+
+	if len(in.CapacityProviders) > 0 {
+		var strategies ecs.ClusterCapacityProvidersDefaultCapacityProviderStrategyArray
+		for _, s := range in.DefaultCapacityProviderStrategies {
+			strategies = append(strategies, &ecs.ClusterCapacityProvidersDefaultCapacityProviderStrategyArgs{
+				CapacityProvider: pulumi.String(s.CapacityProvider),
+				Weight:           pulumi.Int(s.Weight),
+				Base:             pulumi.Int(s.Base),
+			})
+		}
+
+		providers, err := ecs.NewClusterCapacityProviders(ctx, resourceName+"-capacity-providers", &ecs.ClusterCapacityProvidersArgs{
+			ClusterName:                       cluster.Name,
+			CapacityProviders:                 pulumi.ToStringArray(in.CapacityProviders),
+			DefaultCapacityProviderStrategies: strategies,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		deps = append(deps, providers)
+	}
+
+	return &dto.ECSClusterOutput{
+		ClusterName: cluster.Name,
+		ClusterArn:  cluster.Arn,
+	}, deps, nil
+}
+
+// capacityProvidersOf returns the distinct capacity providers used by the strategies
+func capacityProvidersOf(strategies []dto.CapacityProviderStrategy) []string {
+	var out []string
+	for _, s := range strategies {
+		if !slices.Contains(out, s.CapacityProvider) {
+			out = append(out, s.CapacityProvider)
+		}
+	}
+	return out
+}
+
+// serviceCapacityProviderStrategies maps the strategies to the ECS service block
+func serviceCapacityProviderStrategies(strategies []dto.CapacityProviderStrategy) ecs.ServiceCapacityProviderStrategyArray {
+	out := make(ecs.ServiceCapacityProviderStrategyArray, 0, len(strategies))
+	for _, s := range strategies {
+		out = append(out, &ecs.ServiceCapacityProviderStrategyArgs{
+			CapacityProvider: pulumi.String(s.CapacityProvider),
+			Weight:           pulumi.Int(s.Weight),
+			Base:             pulumi.Int(s.Base),
+		})
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package vtech_aws
+
+import (
+	"strings"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ecsDeployment is the ECSDeployment with the Env defaults applied
+type ecsDeployment struct {
+	CircuitBreaker                bool
+	Rollback                      bool
+	MinimumHealthyPercent         int
+	MaximumPercent                int
+	AlarmNames                    []string
+	AlarmRollback                 bool
+	HealthCheckGracePeriodSeconds int
+	EnableExecuteCommand          bool
+}
+
+func isProductionEnv(env string) bool {
+	switch strings.ToLower(env) {
+	case "prod", "prd", "production":
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveECSDeployment applies the production / non-production defaults
+func resolveECSDeployment(in dto.ECSInput) ecsDeployment {
+	d := in.Deployment
+	prod := isProductionEnv(in.Env)
+
+	minHealthy := 50
+	if prod {
+		minHealthy = 100
+	}
+
+	return ecsDeployment{
+		CircuitBreaker:                opt.Coalesce(d.CircuitBreaker, true),
+		Rollback:                      opt.Coalesce(d.Rollback, prod),
+		MinimumHealthyPercent:         opt.Coalesce(d.MinimumHealthyPercent, minHealthy),
+		MaximumPercent:                opt.Coalesce(d.MaximumPercent, 200),
+		AlarmNames:                    d.AlarmNames,
+		AlarmRollback:                 opt.Coalesce(d.AlarmRollback, true),
+		HealthCheckGracePeriodSeconds: opt.Coalesce(d.HealthCheckGracePeriodSeconds, 60),
+		EnableExecuteCommand:          opt.Coalesce(d.EnableExecuteCommand, !prod),
+	}
+}
+
+// applyServiceDeployment sets the deployment configuration on the service args
+func applyServiceDeployment(args *ecs.ServiceArgs, d ecsDeployment, withLoadBalancer bool) {
+	args.DeploymentCircuitBreaker = &ecs.ServiceDeploymentCircuitBreakerArgs{
+		Enable:   pulumi.Bool(d.CircuitBreaker),
+		Rollback: pulumi.Bool(d.CircuitBreaker && d.Rollback),
+	}
+	args.DeploymentMinimumHealthyPercent = pulumi.Int(d.MinimumHealthyPercent)
+	args.DeploymentMaximumPercent = pulumi.Int(d.MaximumPercent)
+	args.EnableExecuteCommand = pulumi.Bool(d.EnableExecuteCommand)
+
+	if len(d.AlarmNames) > 0 {
+		args.Alarms = &ecs.ServiceAlarmsArgs{
+			AlarmNames: pulumi.ToStringArray(d.AlarmNames),
+			Enable:     pulumi.Bool(true),
+			Rollback:   pulumi.Bool(d.AlarmRollback),
+		}
+	}
+
+	if withLoadBalancer {
+		args.HealthCheckGracePeriodSeconds = pulumi.Int(d.HealthCheckGracePeriodSeconds)
+	}
+}
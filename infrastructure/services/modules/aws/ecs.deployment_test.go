@@ -0,0 +1,74 @@
+package vtech_aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/ptr"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+)
+
+func TestResolveECSDeployment(t *testing.T) {
+	tests := []struct {
+		name string
+		in   dto.ECSInput
+		want ecsDeployment
+	}{
+		{
+			name: "dev defaults",
+			in:   dto.ECSInput{Env: "dev"},
+			want: ecsDeployment{
+				CircuitBreaker:                true,
+				Rollback:                      false,
+				MinimumHealthyPercent:         50,
+				MaximumPercent:                200,
+				AlarmRollback:                 true,
+				HealthCheckGracePeriodSeconds: 60,
+				EnableExecuteCommand:          true,
+			},
+		},
+		{
+			name: "prod defaults",
+			in:   dto.ECSInput{Env: "Production"},
+			want: ecsDeployment{
+				CircuitBreaker:                true,
+				Rollback:                      true,
+				MinimumHealthyPercent:         100,
+				MaximumPercent:                200,
+				AlarmRollback:                 true,
+				HealthCheckGracePeriodSeconds: 60,
+				EnableExecuteCommand:          false,
+			},
+		},
+		{
+			name: "overrides",
+			in: dto.ECSInput{Env: "prod", Deployment: dto.ECSDeployment{
+				CircuitBreaker:                ptr.Const(false),
+				Rollback:                      ptr.Const(false),
+				MinimumHealthyPercent:         ptr.Const(0),
+				MaximumPercent:                ptr.Const(150),
+				AlarmNames:                    []string{"5xx"},
+				AlarmRollback:                 ptr.Const(false),
+				HealthCheckGracePeriodSeconds: ptr.Const(120),
+				EnableExecuteCommand:          ptr.Const(true),
+			}},
+			want: ecsDeployment{
+				CircuitBreaker:                false,
+				Rollback:                      false,
+				MinimumHealthyPercent:         0,
+				MaximumPercent:                150,
+				AlarmNames:                    []string{"5xx"},
+				AlarmRollback:                 false,
+				HealthCheckGracePeriodSeconds: 120,
+				EnableExecuteCommand:          true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveECSDeployment(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveECSDeployment() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package vtech_aws
 
 import (
-	"encoding/json"
 	"fmt"
 
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
 	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
-	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/appautoscaling"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/cloudwatch"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
@@ -14,255 +14,173 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
-func (mod AWSModule) CreateServiceStandAlone(ctx *pulumi.Context, baseName string, in dto.ECSInput) (*ecs.Service, error) {
-	// Cluster
-	clusterName := pulumi.String(baseName + "-ecs-cluster")
-	if _, err := ecs.NewCluster(ctx, baseName+"-cluster", &ecs.ClusterArgs{
-		Name: clusterName,
-		Tags: mod.DefaultTags,
-	}); err != nil {
-		return nil, err
-	}
-
-	// CloudWatch Log Group
-	retention := 14
-	if in.LogRetentionDays > 0 {
-		retention = in.LogRetentionDays
-	}
-	lg, err := cloudwatch.NewLogGroup(ctx, baseName+"-lg", &cloudwatch.LogGroupArgs{
-		Name:            pulumi.String(in.LogGroupName),
-		RetentionInDays: pulumi.Int(retention),
-		Tags:            mod.DefaultTags,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// IAM roles (execution + task)
-	execRole, err := iam.NewRole(ctx, baseName+"-exec-role", &iam.RoleArgs{
-		Name: pulumi.String(baseName + "-ecsTaskExecutionRole"),
-		AssumeRolePolicy: pulumi.String(`{
+const ecsTasksAssumeRolePolicy = `{
 		  "Version":"2012-10-17",
 		  "Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]
-		}`),
-		Tags: mod.DefaultTags,
-	})
-	if err != nil {
-		return nil, err
-	}
-	if _, err = iam.NewRolePolicyAttachment(ctx, baseName+"-exec-pol", &iam.RolePolicyAttachmentArgs{
-		Role:      execRole.Name,
-		PolicyArn: pulumi.String("arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"),
-	}); err != nil {
-		return nil, err
-	}
+		}`
 
-	taskRole, err := iam.NewRole(ctx, baseName+"-task-role", &iam.RoleArgs{
-		Name: pulumi.String(baseName + "-ecsTaskRole"),
-		AssumeRolePolicy: pulumi.String(`{
-		  "Version":"2012-10-17",
-		  "Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]
-		}`),
-		Tags: mod.DefaultTags,
-	})
+// ecsTaskResources groups the resources shared by every ECS workload
+type ecsTaskResources struct {
+	LogGroup       *cloudwatch.LogGroup
+	ExecutionRole  *iam.Role
+	TaskRole       *iam.Role
+	TaskDefinition *ecs.TaskDefinition
+}
+
+// CreateServiceStandAlone creates a Fargate service like CreateService and,
+// optionally, a Route53 A-record pointing to the task public IP.
+// With a load balancer the record is the alias created by CreateService
+func (mod AWSModule) CreateServiceStandAlone(ctx *pulumi.Context, baseName string, in dto.ECSInput) (*dto.ECSOutput, error) {
+	out, err := mod.CreateService(ctx, baseName, in)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = iam.NewRolePolicy(ctx, baseName+"-task-logs", &iam.RolePolicyArgs{
-		Name: pulumi.String(baseName + "-log-policy"),
-		Role: taskRole.Name,
-		Policy: pulumi.String(fmt.Sprintf(`{
-		  "Version":"2012-10-17",
-		  "Statement":[
-		    {"Sid":"Logs","Effect":"Allow",
-		      "Action":["logs:CreateLogGroup","logs:CreateLogStream","logs:PutLogEvents","logs:DescribeLogStreams"],
-		      "Resource":"arn:aws:logs:%s:%s:*"}
-		  ]}`, in.Region, in.AccountID)),
-	}); err != nil {
-		return nil, err
-	}
-	if in.AttachS3FullAccess {
-		_, _ = iam.NewRolePolicyAttachment(ctx, baseName+"-task-s3", &iam.RolePolicyAttachmentArgs{
-			Role:      taskRole.Name,
-			PolicyArn: pulumi.String("arn:aws:iam::aws:policy/AmazonS3FullAccess"),
-		})
+	if in.LoadBalancer != nil {
+		return out, nil
 	}
-	if in.CustomInlinePolicyJSON != nil && *in.CustomInlinePolicyJSON != "" {
-		_, _ = iam.NewRolePolicy(ctx, baseName+"-task-custom", &iam.RolePolicyArgs{
-			Name:   pulumi.String(baseName + "-custom-policy"),
-			Role:   taskRole.Name,
-			Policy: pulumi.String(*in.CustomInlinePolicyJSON),
+
+	// Route53 record (OPZIONALE)
+	if in.HostedZoneId != nil && *in.HostedZoneId != "" && in.RecordName != nil && *in.RecordName != "" && in.BackendPublicIp != nil && *in.BackendPublicIp != "" {
+		ttl := 60
+		if in.RecordTTL != nil && *in.RecordTTL > 0 {
+			ttl = *in.RecordTTL
+		}
+		// Se RecordName è relativo (es. "api"), Route53 lo normalizza con il nome della zona.
+		_, err := route53.NewRecord(ctx, baseName+"-dns", &route53.RecordArgs{
+			ZoneId: pulumi.String(*in.HostedZoneId),
+			Name:   pulumi.String(*in.RecordName), // "api" o "api.miodominio.com"
+			Type:   pulumi.String("A"),
+			Ttl:    pulumi.Int(ttl),
+			Records: pulumi.ToStringArray([]string{
+				*in.BackendPublicIp,
+			}),
 		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ctx.Log.Info("[DNS] Skipping Route53 A-record: missing HostedZoneId/RecordName/BackendPublicIp", nil)
 	}
 
-	// Container defs
-	appContainer := map[string]any{
-		"name":        baseName + "-container",
-		"image":       in.EcrImageUrl,
-		"essential":   true,
-		"environment": in.EnvVars, // []EnvVar
-		"portMappings": []dto.PortMapping{{
-			Protocol: "tcp", ContainerPort: in.ContainerPort, HostPort: in.HostPort,
-		}},
-		"logConfiguration": map[string]any{
-			"logDriver": "awslogs",
-			"options": map[string]string{
-				"awslogs-group":         in.LogGroupName,
-				"awslogs-region":        in.Region,
-				"awslogs-stream-prefix": "ecs",
-			},
-		},
-	}
-	containers := []any{appContainer}
-	if in.DDog.Enable {
-		containers = append(containers, map[string]any{
-			"name":         baseName + "-ddog",
-			"image":        in.DDog.Image,
-			"environment":  in.DDog.Env,
-			"portMappings": in.DDog.PortMappings,
-			"essential":    false,
-			"logConfiguration": map[string]any{
-				"logDriver": "awslogs",
-				"options": map[string]string{
-					"awslogs-group":         in.LogGroupName,
-					"awslogs-region":        in.Region,
-					"awslogs-stream-prefix": "ddog",
-				},
-			},
+	return out, nil
+}
+
+// CreateService creates cluster (unless ECSInput.Cluster is given), log group, IAM roles,
+// task definition, Fargate service and autoscaling, returning every created resource identifier.
+// With ECSInput.LoadBalancer the service is registered behind an ALB/NLB
+// and the optional Route53 record is an alias to the load balancer
+func (mod AWSModule) CreateService(ctx *pulumi.Context, baseName string, in dto.ECSInput) (*dto.ECSOutput, error) {
+	// Cluster (esistente oppure dedicato al servizio)
+	cluster := in.Cluster
+	var clusterDeps []pulumi.Resource
+	if cluster == nil {
+		var err error
+		cluster, clusterDeps, err = mod.createCluster(ctx, baseName+"-cluster", dto.ECSClusterInput{
+			Name:              baseName + "-ecs-cluster",
+			ContainerInsights: in.ContainerInsights,
+			CapacityProviders: capacityProvidersOf(in.CapacityProviderStrategies),
 		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	cdef, _ := json.Marshal(containers)
 
-	// Task Definition (Fargate)
-	td, err := ecs.NewTaskDefinition(ctx, baseName+"-taskdef", &ecs.TaskDefinitionArgs{
-		Family:                  pulumi.String(baseName + "-task"),
-		NetworkMode:             pulumi.String("awsvpc"),
-		RequiresCompatibilities: pulumi.ToStringArray([]string{"FARGATE"}),
-		Cpu:                     pulumi.String(in.TaskCPU),
-		Memory:                  pulumi.String(in.TaskMemory),
-		ExecutionRoleArn:        execRole.Arn,
-		TaskRoleArn:             taskRole.Arn,
-		ContainerDefinitions:    pulumi.String(cdef),
-		Tags:                    mod.DefaultTags,
-	})
+	task, err := mod.createTaskResources(ctx, baseName, in, resolveECSDeployment(in).EnableExecuteCommand)
 	if err != nil {
 		return nil, err
 	}
 
-	// Service (pubblico, NO LB)
-	var subnets pulumi.StringArray
-	for _, s := range in.SubnetIds {
-		subnets = append(subnets, s)
-	}
-	var sgs pulumi.StringArray
-	for _, sg := range in.SecurityGroupIds {
-		sgs = append(sgs, sg)
+	var lbRes *ecsLoadBalancerResources
+	if in.LoadBalancer != nil {
+		lbRes, err = mod.createServiceLoadBalancer(ctx, baseName, in)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	svc, err := ecs.NewService(ctx, baseName+"-svc", &ecs.ServiceArgs{
+	// ECS Service — NB: senza LB l'IP pubblico del task può cambiare ai redeploy
+	svcArgs := &ecs.ServiceArgs{
 		Name:           pulumi.String(fmt.Sprintf("%s-service", baseName)),
-		Cluster:        clusterName,
-		TaskDefinition: td.Arn,
+		Cluster:        cluster.ClusterName,
+		TaskDefinition: task.TaskDefinition.Arn,
 		LaunchType:     pulumi.String("FARGATE"),
 		DesiredCount:   pulumi.Int(in.DesiredCount),
 		NetworkConfiguration: &ecs.ServiceNetworkConfigurationArgs{
 			AssignPublicIp: pulumi.Bool(in.AssignPublicIp),
-			Subnets:        subnets,
-			SecurityGroups: sgs,
+			Subnets:        toStringArray(in.SubnetIds),
+			SecurityGroups: toStringArray(in.SecurityGroupIds),
 		},
 		Tags: mod.DefaultTags,
-	})
+	}
+	if len(in.CapacityProviderStrategies) > 0 {
+		svcArgs.LaunchType = nil
+		svcArgs.CapacityProviderStrategies = serviceCapacityProviderStrategies(in.CapacityProviderStrategies)
+	}
+	applyServiceDeployment(svcArgs, resolveECSDeployment(in), lbRes != nil)
+
+	svcDeps := clusterDeps
+	if lbRes != nil {
+		svcArgs.LoadBalancers = serviceLoadBalancers(baseName, in, lbRes)
+		svcDeps = append(svcDeps, lbRes.Dependencies...)
+	}
+
+	svc, err := ecs.NewService(ctx, baseName+"-svc", svcArgs, pulumi.DependsOn(svcDeps))
 	if err != nil {
 		return nil, err
 	}
 
-	// Autoscaling DesiredCount
-	resID := pulumi.Sprintf("service/%s/%s", clusterName, svc.Name)
-	asgTarget, err := appautoscaling.NewTarget(ctx, baseName+"-as-target", &appautoscaling.TargetArgs{
-		MaxCapacity:       pulumi.Int(in.MaxCapacity),
-		MinCapacity:       pulumi.Int(in.MinCapacity),
-		ResourceId:        resID,
-		ScalableDimension: pulumi.String("ecs:service:DesiredCount"),
-		ServiceNamespace:  pulumi.String("ecs"),
-	})
+	asgTarget, err := mod.createServiceAutoscaling(ctx, baseName, cluster.ClusterName, svc, in, lbRes)
 	if err != nil {
 		return nil, err
 	}
-	_, _ = appautoscaling.NewPolicy(ctx, baseName+"-as-mem", &appautoscaling.PolicyArgs{
-		PolicyType:        pulumi.String("TargetTrackingScaling"),
-		ResourceId:        asgTarget.ResourceId,
-		ScalableDimension: asgTarget.ScalableDimension,
-		ServiceNamespace:  asgTarget.ServiceNamespace,
-		TargetTrackingScalingPolicyConfiguration: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationArgs{
-			PredefinedMetricSpecification: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
-				PredefinedMetricType: pulumi.String("ECSServiceAverageMemoryUtilization"),
-			},
-			TargetValue: pulumi.Float64(in.TargetMemory),
-		},
-	})
-	_, _ = appautoscaling.NewPolicy(ctx, baseName+"-as-cpu", &appautoscaling.PolicyArgs{
-		PolicyType:        pulumi.String("TargetTrackingScaling"),
-		ResourceId:        asgTarget.ResourceId,
-		ScalableDimension: asgTarget.ScalableDimension,
-		ServiceNamespace:  asgTarget.ServiceNamespace,
-		TargetTrackingScalingPolicyConfiguration: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationArgs{
-			PredefinedMetricSpecification: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
-				PredefinedMetricType: pulumi.String("ECSServiceAverageCPUUtilization"),
-			},
-			TargetValue: pulumi.Float64(in.TargetCPU),
-		},
-	})
 
-	// Log subscription → Lambda (opzionale)
-	if in.LogSubscriptionLambdaName != nil && in.LogSubscriptionLambdaArn != nil {
-		perm, _ := lambda.NewPermission(ctx, baseName+"-lg-lambda-perm", &lambda.PermissionArgs{
-			Action:    pulumi.String("lambda:InvokeFunction"),
-			Function:  pulumi.String(*in.LogSubscriptionLambdaName),
-			Principal: pulumi.String(fmt.Sprintf("logs.%s.amazonaws.com", in.Region)),
-			SourceArn: pulumi.Sprintf("%s:*", lg.Arn),
-		})
-		_, _ = cloudwatch.NewLogSubscriptionFilter(ctx, baseName+"-lg-sub", &cloudwatch.LogSubscriptionFilterArgs{
-			Name:           pulumi.String(baseName + "-lambda-subscription"),
-			DestinationArn: pulumi.String(*in.LogSubscriptionLambdaArn),
-			FilterPattern:  pulumi.String(""),
-			LogGroup:       lg.Name,
-		}, pulumi.DependsOn([]pulumi.Resource{perm}))
+	if err := mod.createLogSubscription(ctx, baseName, task.LogGroup, in); err != nil {
+		return nil, err
 	}
 
-	// Route53 record (OPZIONALE)
-	if in.HostedZoneId != nil && *in.HostedZoneId != "" && in.RecordName != nil && *in.RecordName != "" && in.BackendPublicIp != nil && *in.BackendPublicIp != "" {
-		ttl := 60
-		if in.RecordTTL != nil && *in.RecordTTL > 0 {
-			ttl = *in.RecordTTL
-		}
-		// Se RecordName è relativo (es. "api"), Route53 lo normalizza con il nome della zona.
-		_, err := route53.NewRecord(ctx, baseName+"-dns", &route53.RecordArgs{
-			ZoneId: pulumi.String(*in.HostedZoneId),
-			Name:   pulumi.String(*in.RecordName), // "api" o "api.miodominio.com"
-			Type:   pulumi.String("A"),
-			Ttl:    pulumi.Int(ttl),
-			Records: pulumi.ToStringArray([]string{
-				*in.BackendPublicIp,
-			}),
-		})
-		if err != nil {
+	out := &dto.ECSOutput{
+		ClusterName:           cluster.ClusterName,
+		ClusterArn:            cluster.ClusterArn,
+		ServiceName:           svc.Name,
+		ServiceArn:            svc.ID().ToStringOutput(), // l'ID del servizio ECS è il suo ARN
+		TaskDefinitionArn:     task.TaskDefinition.Arn,
+		TaskExecutionRoleArn:  task.ExecutionRole.Arn,
+		TaskRoleArn:           task.TaskRole.Arn,
+		LogGroupName:          task.LogGroup.Name,
+		LogGroupArn:           task.LogGroup.Arn,
+		AutoscalingResourceId: asgTarget.ResourceId,
+	}
+
+	if lbRes != nil {
+		if err := mod.createLoadBalancerRecord(ctx, baseName, in, lbRes); err != nil {
 			return nil, err
 		}
-	} else {
-		ctx.Log.Info("[DNS] Skipping Route53 A-record: missing HostedZoneId/RecordName/BackendPublicIp", nil)
+		out.LoadBalancerArn = lbRes.LoadBalancerArn
+		out.LoadBalancerDnsName = lbRes.DnsName
+		out.ListenerArn = lbRes.ListenerArn
+		out.TargetGroupArn = lbRes.TargetGroup.Arn
 	}
 
-	return svc, nil
+	return out, nil
 }
 
-func (mod AWSModule) CreateService(ctx *pulumi.Context, baseName string, in dto.ECSInput) error {
-	// Cluster
-	clusterName := pulumi.String(baseName + "-ecs-cluster")
-	if _, err := ecs.NewCluster(ctx, baseName+"-cluster", &ecs.ClusterArgs{
-		Name: clusterName,
-		Tags: mod.DefaultTags,
-	}); err != nil {
-		return err
+// createTaskResources creates log group, execution/task roles and the Fargate task definition.
+// execCommand grants the task role the SSM channels used by ECS Exec
+func (mod AWSModule) createTaskResources(ctx *pulumi.Context, baseName string, in dto.ECSInput, execCommand bool) (*ecsTaskResources, error) {
+	// Container definitions
+	containers := []dto.ContainerDefinition{appContainer(baseName, in)}
+	if in.DDog.Enable {
+		containers = append(containers, mappers.DatadogSidecarContainer(baseName+"-ddog", in.DDog, in.LogGroupName, in.Region))
+	}
+	for _, c := range in.Containers {
+		if c.LogConfiguration == nil {
+			c.LogConfiguration = mappers.AWSLogsConfiguration(in.LogGroupName, in.Region, c.Name)
+		}
+		containers = append(containers, c)
+	}
+	directSecrets, err := applyECSSecrets(containers, appContainerName(baseName, in), in.Secrets)
+	if err != nil {
+		return nil, err
 	}
 
 	// CloudWatch Log Group (Logs)
@@ -276,109 +194,90 @@ func (mod AWSModule) CreateService(ctx *pulumi.Context, baseName string, in dto.
 		Tags:            mod.DefaultTags,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// IAM: Task Execution Role
 	execRole, err := iam.NewRole(ctx, baseName+"-exec-role", &iam.RoleArgs{
-		Name: pulumi.String(baseName + "-ecsTaskExecutionRole"),
-		AssumeRolePolicy: pulumi.String(`{
-		  "Version":"2012-10-17",
-		  "Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]
-		}`),
-		Tags: mod.DefaultTags,
+		Name:             pulumi.String(baseName + "-ecsTaskExecutionRole"),
+		AssumeRolePolicy: pulumi.String(ecsTasksAssumeRolePolicy),
+		Tags:             mod.DefaultTags,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if _, err = iam.NewRolePolicyAttachment(ctx, baseName+"-exec-pol", &iam.RolePolicyAttachmentArgs{
 		Role:      execRole.Name,
 		PolicyArn: pulumi.String("arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"),
 	}); err != nil {
-		return err
+		return nil, err
+	}
+	// Secrets iniettati nei container (solo gli ARN indicati)
+	if err := mod.createSecretsPolicy(ctx, baseName, execRole, in.Secrets, directSecrets); err != nil {
+		return nil, err
 	}
 
 	// IAM: Task Role
 	taskRole, err := iam.NewRole(ctx, baseName+"-task-role", &iam.RoleArgs{
-		Name: pulumi.String(baseName + "-ecsTaskRole"),
-		AssumeRolePolicy: pulumi.String(`{
-		  "Version":"2012-10-17",
-		  "Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]
-		}`),
-		Tags: mod.DefaultTags,
+		Name:             pulumi.String(baseName + "-ecsTaskRole"),
+		AssumeRolePolicy: pulumi.String(ecsTasksAssumeRolePolicy),
+		Tags:             mod.DefaultTags,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Policy minima per logs
+	logsDoc := iam.GetPolicyDocumentOutput(ctx, iam.GetPolicyDocumentOutputArgs{
+		Statements: mod.Policies.Build(policy.StatementSpec{
+			Groups:    []policy.PolicyGroup{policy.CLOUDWATCH_LOGS},
+			Resources: []string{fmt.Sprintf("arn:aws:logs:%s:%s:*", in.Region, in.AccountID)},
+		}),
+	})
 	if _, err = iam.NewRolePolicy(ctx, baseName+"-task-logs", &iam.RolePolicyArgs{
-		Name: pulumi.String(baseName + "-log-policy"),
-		Role: taskRole.Name,
-		Policy: pulumi.String(fmt.Sprintf(`{
-		  "Version":"2012-10-17",
-		  "Statement":[
-		    {"Sid":"Logs","Effect":"Allow",
-		      "Action":["logs:CreateLogGroup","logs:CreateLogStream","logs:PutLogEvents","logs:DescribeLogStreams"],
-		      "Resource":"arn:aws:logs:%s:%s:*"}
-		  ]}`, in.Region, in.AccountID)),
+		Name:   pulumi.String(baseName + "-log-policy"),
+		Role:   taskRole.Name,
+		Policy: logsDoc.Json(),
 	}); err != nil {
-		return err
+		return nil, err
 	}
-	// Extra S3 (opzionale)
-	if in.AttachS3FullAccess {
-		_, _ = iam.NewRolePolicyAttachment(ctx, baseName+"-task-s3", &iam.RolePolicyAttachmentArgs{
-			Role:      taskRole.Name,
-			PolicyArn: pulumi.String("arn:aws:iam::aws:policy/AmazonS3FullAccess"),
+	// Statement applicativi (opzionali)
+	if len(in.TaskRoleStatements) > 0 {
+		customDoc := iam.GetPolicyDocumentOutput(ctx, iam.GetPolicyDocumentOutputArgs{
+			Statements: mod.Policies.Build(in.TaskRoleStatements...),
 		})
-	}
-	// Inline custom (opzionale)
-	if in.CustomInlinePolicyJSON != nil && *in.CustomInlinePolicyJSON != "" {
-		_, _ = iam.NewRolePolicy(ctx, baseName+"-task-custom", &iam.RolePolicyArgs{
+		if _, err = iam.NewRolePolicy(ctx, baseName+"-task-custom", &iam.RolePolicyArgs{
 			Name:   pulumi.String(baseName + "-custom-policy"),
 			Role:   taskRole.Name,
-			Policy: pulumi.String(*in.CustomInlinePolicyJSON),
-		})
+			Policy: customDoc.Json(),
+		}); err != nil {
+			return nil, err
+		}
 	}
-
-	// Container definitions
-	appContainer := map[string]interface{}{
-		"name":        baseName + "-container",
-		"image":       in.EcrImageUrl,
-		"essential":   true,
-		"environment": in.EnvVars, // []EnvVar
-		"portMappings": []dto.PortMapping{{
-			Protocol: "tcp", ContainerPort: in.ContainerPort, HostPort: in.HostPort,
-		}},
-		"logConfiguration": map[string]interface{}{
-			"logDriver": "awslogs",
-			"options": map[string]string{
-				"awslogs-group":         in.LogGroupName,
-				"awslogs-region":        in.Region,
-				"awslogs-stream-prefix": "ecs",
-			},
-		},
+	// ECS Exec (debug): canali SSM per `aws ecs execute-command`
+	if execCommand {
+		execDoc := iam.GetPolicyDocumentOutput(ctx, iam.GetPolicyDocumentOutputArgs{
+			Statements: mod.Policies.Build(policy.StatementSpec{
+				Groups:    []policy.PolicyGroup{policy.ECS_EXEC},
+				Resources: policy.AllResources(),
+			}),
+		})
+		if _, err = iam.NewRolePolicy(ctx, baseName+"-task-exec-command", &iam.RolePolicyArgs{
+			Name:   pulumi.String(baseName + "-exec-command-policy"),
+			Role:   taskRole.Name,
+			Policy: execDoc.Json(),
+		}); err != nil {
+			return nil, err
+		}
 	}
-
-	containers := []interface{}{appContainer}
-	if in.DDog.Enable {
-		dd := map[string]interface{}{
-			"name":         baseName + "-ddog",
-			"image":        in.DDog.Image,
-			"environment":  in.DDog.Env,
-			"portMappings": in.DDog.PortMappings,
-			"essential":    false,
-			"logConfiguration": map[string]interface{}{
-				"logDriver": "awslogs",
-				"options": map[string]string{
-					"awslogs-group":         in.LogGroupName,
-					"awslogs-region":        in.Region,
-					"awslogs-stream-prefix": "ddog",
-				},
-			},
+	// Managed policy (opzionali)
+	for i, arn := range in.TaskRoleManagedPolicyArns {
+		if _, err = iam.NewRolePolicyAttachment(ctx, fmt.Sprintf("%s-task-managed-%d", baseName, i), &iam.RolePolicyAttachmentArgs{
+			Role:      taskRole.Name,
+			PolicyArn: pulumi.String(arn),
+		}); err != nil {
+			return nil, err
 		}
-		containers = append(containers, dd)
 	}
-	cdef, _ := json.Marshal(containers)
 
 	// Task Definition (Fargate)
 	td, err := ecs.NewTaskDefinition(ctx, baseName+"-taskdef", &ecs.TaskDefinitionArgs{
@@ -389,103 +288,82 @@ func (mod AWSModule) CreateService(ctx *pulumi.Context, baseName string, in dto.
 		Memory:                  pulumi.String(in.TaskMemory),
 		ExecutionRoleArn:        execRole.Arn,
 		TaskRoleArn:             taskRole.Arn,
-		ContainerDefinitions:    pulumi.String(cdef),
+		ContainerDefinitions:    mappers.ContainerDefinitionsJSON(containers),
+		Volumes:                 in.Volumes,
 		Tags:                    mod.DefaultTags,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// ECS Service (senza LB) — NB: IP pubblico del task può cambiare ai redeploy
-	var subnets pulumi.StringArray
-	for _, s := range in.SubnetIds {
-		subnets = append(subnets, s)
-	}
-	var sgs pulumi.StringArray
-	for _, sg := range in.SecurityGroupIds {
-		sgs = append(sgs, sg)
+	return &ecsTaskResources{
+		LogGroup:       lg,
+		ExecutionRole:  execRole,
+		TaskRole:       taskRole,
+		TaskDefinition: td,
+	}, nil
+}
+
+// appContainerName is the name of the application container ("<baseName>-container" by default)
+func appContainerName(baseName string, in dto.ECSInput) string {
+	if in.AppContainer.Name != "" {
+		return in.AppContainer.Name
 	}
+	return baseName + "-container"
+}
 
-	svc, err := ecs.NewService(ctx, baseName+"-svc", &ecs.ServiceArgs{
-		Name:           pulumi.String(fmt.Sprintf("%s-service", baseName)),
-		Cluster:        clusterName,
-		TaskDefinition: td.Arn,
-		LaunchType:     pulumi.String("FARGATE"),
-		DesiredCount:   pulumi.Int(in.DesiredCount),
-		NetworkConfiguration: &ecs.ServiceNetworkConfigurationArgs{
-			AssignPublicIp: pulumi.Bool(in.AssignPublicIp),
-			Subnets:        subnets,
-			SecurityGroups: sgs,
-		},
-		Tags: mod.DefaultTags,
-	})
-	if err != nil {
-		return err
+// appContainer builds the application container from the ECSInput task fields
+// merged with the AppContainer advanced options
+func appContainer(baseName string, in dto.ECSInput) dto.ContainerDefinition {
+	c := in.AppContainer
+	c.Name = appContainerName(baseName, in)
+	if c.Image == nil {
+		c.Image = in.EcrImageUrl
+	}
+	if len(c.PortMappings) == 0 {
+		c.PortMappings = []dto.PortMapping{{
+			Protocol: "tcp", ContainerPort: in.ContainerPort, HostPort: in.HostPort,
+		}}
 	}
+	if len(c.Environment) == 0 {
+		c.Environment = in.EnvVars
+	}
+	if c.LogConfiguration == nil {
+		c.LogConfiguration = mappers.AWSLogsConfiguration(in.LogGroupName, in.Region, "ecs")
+	}
+	return c
+}
 
-	// Autoscaling: DesiredCount (CPU / Memory target tracking)
-	resID := pulumi.Sprintf("service/%s/%s", clusterName, svc.Name)
+// createLogSubscription forwards the log group to a Lambda (opzionale)
+func (mod AWSModule) createLogSubscription(ctx *pulumi.Context, baseName string, lg *cloudwatch.LogGroup, in dto.ECSInput) error {
+	if in.LogSubscriptionLambdaName == nil || in.LogSubscriptionLambdaArn == nil {
+		return nil
+	}
 
-	asgTarget, err := appautoscaling.NewTarget(ctx, baseName+"-as-target", &appautoscaling.TargetArgs{
-		MaxCapacity:       pulumi.Int(in.MaxCapacity),
-		MinCapacity:       pulumi.Int(in.MinCapacity),
-		ResourceId:        resID,
-		ScalableDimension: pulumi.String("ecs:service:DesiredCount"),
-		ServiceNamespace:  pulumi.String("ecs"),
+	perm, err := lambda.NewPermission(ctx, baseName+"-lg-lambda-perm", &lambda.PermissionArgs{
+		Action:    pulumi.String("lambda:InvokeFunction"),
+		Function:  pulumi.String(*in.LogSubscriptionLambdaName),
+		Principal: pulumi.String(fmt.Sprintf("logs.%s.amazonaws.com", in.Region)),
+		SourceArn: pulumi.Sprintf("%s:*", lg.Arn),
 	})
 	if err != nil {
 		return err
 	}
 
-	_, _ = appautoscaling.NewPolicy(ctx, baseName+"-as-mem", &appautoscaling.PolicyArgs{
-		PolicyType:        pulumi.String("TargetTrackingScaling"),
-		ResourceId:        asgTarget.ResourceId,
-		ScalableDimension: asgTarget.ScalableDimension,
-		ServiceNamespace:  asgTarget.ServiceNamespace,
-		TargetTrackingScalingPolicyConfiguration: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationArgs{
-			PredefinedMetricSpecification: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
-				PredefinedMetricType: pulumi.String("ECSServiceAverageMemoryUtilization"),
-			},
-			TargetValue: pulumi.Float64(in.TargetMemory),
-		},
-	})
-	_, _ = appautoscaling.NewPolicy(ctx, baseName+"-as-cpu", &appautoscaling.PolicyArgs{
-		PolicyType:        pulumi.String("TargetTrackingScaling"),
-		ResourceId:        asgTarget.ResourceId,
-		ScalableDimension: asgTarget.ScalableDimension,
-		ServiceNamespace:  asgTarget.ServiceNamespace,
-		TargetTrackingScalingPolicyConfiguration: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationArgs{
-			PredefinedMetricSpecification: &appautoscaling.PolicyTargetTrackingScalingPolicyConfigurationPredefinedMetricSpecificationArgs{
-				PredefinedMetricType: pulumi.String("ECSServiceAverageCPUUtilization"),
-			},
-			TargetValue: pulumi.Float64(in.TargetCPU),
-		},
-	})
+	_, err = cloudwatch.NewLogSubscriptionFilter(ctx, baseName+"-lg-sub", &cloudwatch.LogSubscriptionFilterArgs{
+		Name:           pulumi.String(baseName + "-lambda-subscription"),
+		DestinationArn: pulumi.String(*in.LogSubscriptionLambdaArn),
+		FilterPattern:  pulumi.String(""),
+		LogGroup:       lg.Name,
+	}, pulumi.DependsOn([]pulumi.Resource{perm}))
 
-	// Log subscription verso Lambda
-	if in.LogSubscriptionLambdaName != nil && in.LogSubscriptionLambdaArn != nil {
-		perm, _ := lambda.NewPermission(ctx, baseName+"-lg-lambda-perm", &lambda.PermissionArgs{
-			Action:    pulumi.String("lambda:InvokeFunction"),
-			Function:  pulumi.String(*in.LogSubscriptionLambdaName),
-			Principal: pulumi.String(fmt.Sprintf("logs.%s.amazonaws.com", in.Region)),
-			SourceArn: pulumi.Sprintf("%s:*", lg.Arn),
-		})
-		_, _ = cloudwatch.NewLogSubscriptionFilter(ctx, baseName+"-lg-sub", &cloudwatch.LogSubscriptionFilterArgs{
-			Name:           pulumi.String(baseName + "-lambda-subscription"),
-			DestinationArn: pulumi.String(*in.LogSubscriptionLambdaArn),
-			FilterPattern:  pulumi.String(""),
-			LogGroup:       lg.Name,
-		}, pulumi.DependsOn([]pulumi.Resource{perm}))
-	}
+	return err
+}
 
-	// return &dto.ECSOutput{
-	// 	ClusterName:          clusterName.ToStringOutput(),
-	// 	ServiceName:          svc.Name,
-	// 	ServiceArn:           svc.Arn,
-	// 	TaskDefinitionArn:    td.Arn,
-	// 	TaskExecutionRoleArn: execRole.Arn,
-	// 	TaskRoleArn:          taskRole.Arn,
-	// 	LogGroupName:         lg.Name,
-	// },
-	return nil
+func toStringArray(in []pulumi.StringInput) pulumi.StringArray {
+	out := make(pulumi.StringArray, 0, len(in))
+	for _, s := range in {
+		out = append(out, s)
+	}
+	return out
 }
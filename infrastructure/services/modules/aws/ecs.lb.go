@@ -0,0 +1,215 @@
+package vtech_aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/load_balancer"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ecs"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lb"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/route53"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ecsLoadBalancerResources groups the load balancer pieces the ECS service is wired to
+type ecsLoadBalancerResources struct {
+	LbType          string
+	LoadBalancerArn pulumi.StringOutput
+	ArnSuffix       pulumi.StringOutput // "app/<name>/<id>", usato dalle metriche CloudWatch
+	DnsName         pulumi.StringOutput
+	ZoneId          pulumi.StringOutput
+	ListenerArn     pulumi.StringOutput
+	TargetGroup     *lb.TargetGroup
+
+	// Resources the ECS service must wait for (the target group must be attached to the LB)
+	Dependencies []pulumi.Resource
+}
+
+// createServiceLoadBalancer creates (or reuses) the ALB/NLB and its listener,
+// then creates an "ip" target group with the forwarding rule for the ECS service
+func (mod AWSModule) createServiceLoadBalancer(ctx *pulumi.Context, baseName string, in dto.ECSInput) (*ecsLoadBalancerResources, error) {
+	cfg := in.LoadBalancer
+
+	lbType := cfg.LbType
+	if cfg.Create != nil && cfg.Create.LbType != "" {
+		lbType = cfg.Create.LbType
+	}
+	if lbType == "" {
+		lbType = "application"
+	}
+	if lbType == "network" && cfg.Listener == nil {
+		return nil, errors.New("ecs load balancer: a network load balancer requires a new Listener forwarding to the target group")
+	}
+
+	res := &ecsLoadBalancerResources{LbType: lbType}
+
+	// Load balancer
+	switch {
+	case cfg.Create != nil:
+		lbIn := *cfg.Create
+		lbIn.LbType = lbType
+		if lbIn.Tags == nil {
+			lbIn.Tags = mod.DefaultTags
+		}
+		lbRes, err := load_balancer.CreateService(ctx, lbIn)
+		if err != nil {
+			return nil, err
+		}
+		res.LoadBalancerArn = lbRes.Arn
+		res.ArnSuffix = lbRes.ArnSuffix
+		res.DnsName = lbRes.DnsName
+		res.ZoneId = lbRes.ZoneId
+	case cfg.LoadBalancerArn != nil:
+		res.LoadBalancerArn = cfg.LoadBalancerArn.ToStringOutput()
+		res.ArnSuffix = res.LoadBalancerArn.ApplyT(func(arn string) string {
+			// arn:aws:elasticloadbalancing:<region>:<account>:loadbalancer/app/<name>/<id>
+			_, suffix, _ := strings.Cut(arn, ":loadbalancer/")
+			return suffix
+		}).(pulumi.StringOutput)
+		if cfg.LoadBalancerDnsName != nil {
+			res.DnsName = cfg.LoadBalancerDnsName.ToStringOutput()
+		}
+		if cfg.LoadBalancerZoneId != nil {
+			res.ZoneId = cfg.LoadBalancerZoneId.ToStringOutput()
+		}
+	default:
+		return nil, errors.New("ecs load balancer: one of LoadBalancerArn or Create is required")
+	}
+
+	// Target group (Fargate awsvpc → target type "ip")
+	tgIn := cfg.TargetGroup
+	tgIn.TargetType = "ip"
+	if tgIn.Name == "" {
+		tgIn.Name = baseName + "-tg"
+	}
+	if tgIn.Port == 0 {
+		tgIn.Port = in.ContainerPort
+	}
+	if tgIn.Protocol == "" {
+		tgIn.Protocol = "HTTP"
+		if lbType == "network" {
+			tgIn.Protocol = "TCP"
+		}
+	}
+	if tgIn.Tags == nil {
+		tgIn.Tags = mod.DefaultTags
+	}
+	tg, err := load_balancer.CreateTargetGroup(ctx, tgIn)
+	if err != nil {
+		return nil, err
+	}
+	res.TargetGroup = tg
+
+	// Listener
+	switch {
+	case cfg.Listener != nil:
+		lIn := *cfg.Listener
+		if lIn.Name == "" {
+			lIn.Name = baseName
+		}
+		lIn.LoadBalancerArn = res.LoadBalancerArn
+		if lbType == "network" {
+			// NLB: niente listener rule, il listener inoltra direttamente al target group
+			lIn.DefaultTargetGroupArn = tg.Arn
+		}
+		if lIn.Tags == nil {
+			lIn.Tags = mod.DefaultTags
+		}
+		listener, err := load_balancer.CreateListener(ctx, lIn)
+		if err != nil {
+			return nil, err
+		}
+		res.ListenerArn = listener.Arn
+		res.Dependencies = append(res.Dependencies, listener)
+	case cfg.ListenerArn != nil:
+		res.ListenerArn = cfg.ListenerArn.ToStringOutput()
+	default:
+		return nil, errors.New("ecs load balancer: one of ListenerArn or Listener is required")
+	}
+
+	if lbType == "network" {
+		return res, nil
+	}
+
+	// Listener rule (ALB) → forward al target group
+	priority := 100
+	if cfg.RulePriority > 0 {
+		priority = cfg.RulePriority
+	}
+	paths := cfg.PathPatterns
+	if len(paths) == 0 {
+		paths = []string{"/*"}
+	}
+	conditions := lb.ListenerRuleConditionArray{
+		&lb.ListenerRuleConditionArgs{
+			PathPattern: &lb.ListenerRuleConditionPathPatternArgs{
+				Values: pulumi.ToStringArray(paths),
+			},
+		},
+	}
+	if len(cfg.HostHeaders) > 0 {
+		conditions = append(conditions, &lb.ListenerRuleConditionArgs{
+			HostHeader: &lb.ListenerRuleConditionHostHeaderArgs{
+				Values: pulumi.ToStringArray(cfg.HostHeaders),
+			},
+		})
+	}
+
+	rule, err := lb.NewListenerRule(ctx, baseName+"-lb-rule", &lb.ListenerRuleArgs{
+		ListenerArn: res.ListenerArn,
+		Priority:    pulumi.Int(priority),
+		Actions: lb.ListenerRuleActionArray{
+			&lb.ListenerRuleActionArgs{
+				Type:           pulumi.String("forward"),
+				TargetGroupArn: tg.Arn,
+			},
+		},
+		Conditions: conditions,
+		Tags:       mod.DefaultTags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Dependencies = append(res.Dependencies, rule)
+
+	return res, nil
+}
+
+// serviceLoadBalancers maps the target group to the ECS service load_balancer block
+func serviceLoadBalancers(baseName string, in dto.ECSInput, res *ecsLoadBalancerResources) ecs.ServiceLoadBalancerArray {
+	return ecs.ServiceLoadBalancerArray{
+		&ecs.ServiceLoadBalancerArgs{
+			TargetGroupArn: res.TargetGroup.Arn,
+			ContainerName:  pulumi.String(appContainerName(baseName, in)),
+			ContainerPort:  pulumi.Int(in.ContainerPort),
+		},
+	}
+}
+
+// createLoadBalancerRecord points the Route53 record to the load balancer (alias A-record)
+func (mod AWSModule) createLoadBalancerRecord(ctx *pulumi.Context, baseName string, in dto.ECSInput, res *ecsLoadBalancerResources) error {
+	if in.HostedZoneId == nil || *in.HostedZoneId == "" || in.RecordName == nil || *in.RecordName == "" {
+		ctx.Log.Info("[DNS] Skipping Route53 alias record: missing HostedZoneId/RecordName", nil)
+		return nil
+	}
+	if in.LoadBalancer.Create == nil && (in.LoadBalancer.LoadBalancerDnsName == nil || in.LoadBalancer.LoadBalancerZoneId == nil) {
+		return fmt.Errorf("ecs load balancer: LoadBalancerDnsName and LoadBalancerZoneId are required for the %s record", *in.RecordName)
+	}
+
+	_, err := route53.NewRecord(ctx, baseName+"-dns", &route53.RecordArgs{
+		ZoneId: pulumi.String(*in.HostedZoneId),
+		Name:   pulumi.String(*in.RecordName),
+		Type:   pulumi.String("A"),
+		Aliases: route53.RecordAliasArray{
+			&route53.RecordAliasArgs{
+				Name:                 res.DnsName,
+				ZoneId:               res.ZoneId,
+				EvaluateTargetHealth: pulumi.Bool(true),
+			},
+		},
+	})
+
+	return err
+}
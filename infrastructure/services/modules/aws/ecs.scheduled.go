@@ -0,0 +1,162 @@
+package vtech_aws
+
+import (
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CreateScheduledTask creates the task definition (with the same log group and roles of CreateService)
+// and, with a ScheduleExpression, the EventBridge rule running it on the cluster. No ECS service is created
+func (mod AWSModule) CreateScheduledTask(ctx *pulumi.Context, baseName string, in dto.ECSScheduledTaskInput) (*dto.ECSScheduledTaskOutput, error) {
+	// Cluster (esistente oppure dedicato al task)
+	cluster := in.Cluster
+	var clusterDeps []pulumi.Resource
+	if cluster == nil {
+		var err error
+		cluster, clusterDeps, err = mod.createCluster(ctx, baseName+"-cluster", dto.ECSClusterInput{
+			Name:              baseName + "-ecs-cluster",
+			ContainerInsights: in.ContainerInsights,
+			CapacityProviders: capacityProvidersOf(in.CapacityProviderStrategies),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Nessun servizio su cui lanciare `aws ecs execute-command`: niente policy ECS Exec
+	task, err := mod.createTaskResources(ctx, baseName, in.ECSInput, false)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dto.ECSScheduledTaskOutput{
+		ClusterName:          cluster.ClusterName,
+		ClusterArn:           cluster.ClusterArn,
+		TaskDefinitionArn:    task.TaskDefinition.Arn,
+		TaskExecutionRoleArn: task.ExecutionRole.Arn,
+		TaskRoleArn:          task.TaskRole.Arn,
+		LogGroupName:         task.LogGroup.Name,
+		LogGroupArn:          task.LogGroup.Arn,
+	}
+	if in.ScheduleExpression == "" {
+		ctx.Log.Info("[ECS] Skipping EventBridge rule: missing ScheduleExpression, one-off task definition only", nil)
+		return out, nil
+	}
+
+	// IAM: ruolo con cui EventBridge lancia il task
+	invokeRole, err := iam.NewRole(ctx, baseName+"-events-role", &iam.RoleArgs{
+		Name:             pulumi.String(baseName + "-ecsEventsRole"),
+		AssumeRolePolicy: pulumi.String(policy.IAM_EVENTS_ASSUME_ROLE),
+		Tags:             mod.DefaultTags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Il target propaga i tag sul task: RunTask con tag richiede anche ecs:TagResource
+	tagStatement := mod.Policies.Statement(policy.AllResources(), policy.ECS_TAG_RESOURCE)
+	tagStatement.Conditions = iam.GetPolicyDocumentStatementConditionArray{
+		&iam.GetPolicyDocumentStatementConditionArgs{
+			Test:     pulumi.String("StringEquals"),
+			Variable: pulumi.String("ecs:CreateAction"),
+			Values:   pulumi.StringArray{pulumi.String("RunTask")},
+		},
+	}
+	invokeDoc := iam.GetPolicyDocumentOutput(ctx, iam.GetPolicyDocumentOutputArgs{
+		Statements: append(mod.Policies.Build(
+			policy.StatementSpec{
+				Groups:       []policy.PolicyGroup{policy.ECS_RUN_TASK},
+				ResourceArns: pulumi.StringArray{pulumi.Sprintf("%s:*", task.TaskDefinition.ArnWithoutRevision)},
+			},
+			policy.StatementSpec{
+				Groups:       []policy.PolicyGroup{policy.IAM_PASS_ROLE},
+				ResourceArns: pulumi.StringArray{task.ExecutionRole.Arn, task.TaskRole.Arn},
+			},
+		), tagStatement),
+	})
+	if _, err = iam.NewRolePolicy(ctx, baseName+"-events-run-task", &iam.RolePolicyArgs{
+		Name:   pulumi.String(baseName + "-run-task-policy"),
+		Role:   invokeRole.Name,
+		Policy: invokeDoc.Json(),
+	}); err != nil {
+		return nil, err
+	}
+
+	// EventBridge rule
+	state := "ENABLED"
+	if !opt.Coalesce(in.Enabled, true) {
+		state = "DISABLED"
+	}
+	rule, err := cloudwatch.NewEventRule(ctx, baseName+"-schedule", &cloudwatch.EventRuleArgs{
+		Name:               pulumi.String(baseName + "-schedule"),
+		Description:        pulumi.String(in.Description),
+		ScheduleExpression: pulumi.String(in.ScheduleExpression),
+		State:              pulumi.String(state),
+		Tags:               mod.DefaultTags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskCount := 1
+	if in.TaskCount > 0 {
+		taskCount = in.TaskCount
+	}
+	ecsTarget := &cloudwatch.EventTargetEcsTargetArgs{
+		TaskDefinitionArn: task.TaskDefinition.Arn,
+		TaskCount:         pulumi.Int(taskCount),
+		LaunchType:        pulumi.String("FARGATE"),
+		PlatformVersion:   pulumi.String(orDefault(in.PlatformVersion, "LATEST")),
+		NetworkConfiguration: &cloudwatch.EventTargetEcsTargetNetworkConfigurationArgs{
+			AssignPublicIp: pulumi.Bool(in.AssignPublicIp),
+			Subnets:        toStringArray(in.SubnetIds),
+			SecurityGroups: toStringArray(in.SecurityGroupIds),
+		},
+		PropagateTags: pulumi.String("TASK_DEFINITION"),
+		Tags:          mod.DefaultTags,
+	}
+	if len(in.CapacityProviderStrategies) > 0 {
+		ecsTarget.LaunchType = nil
+		var strategies cloudwatch.EventTargetEcsTargetCapacityProviderStrategyArray
+		for _, s := range in.CapacityProviderStrategies {
+			strategies = append(strategies, &cloudwatch.EventTargetEcsTargetCapacityProviderStrategyArgs{
+				CapacityProvider: pulumi.String(s.CapacityProvider),
+				Weight:           pulumi.Int(s.Weight),
+				Base:             pulumi.Int(s.Base),
+			})
+		}
+		ecsTarget.CapacityProviderStrategies = strategies
+	}
+
+	targetArgs := &cloudwatch.EventTargetArgs{
+		Rule:      rule.Name,
+		Arn:       cluster.ClusterArn,
+		RoleArn:   invokeRole.Arn,
+		EcsTarget: ecsTarget,
+	}
+	if in.MaximumRetryAttempts != nil || in.MaximumEventAgeInSeconds != nil {
+		targetArgs.RetryPolicy = &cloudwatch.EventTargetRetryPolicyArgs{
+			MaximumRetryAttempts:     pulumi.IntPtrFromPtr(in.MaximumRetryAttempts),
+			MaximumEventAgeInSeconds: pulumi.IntPtrFromPtr(in.MaximumEventAgeInSeconds),
+		}
+	}
+	if in.DeadLetterQueueArn != nil {
+		targetArgs.DeadLetterConfig = &cloudwatch.EventTargetDeadLetterConfigArgs{
+			Arn: in.DeadLetterQueueArn,
+		}
+	}
+
+	// Il target usa le capacity provider strategy: attende l'associazione al cluster
+	if _, err = cloudwatch.NewEventTarget(ctx, baseName+"-schedule-target", targetArgs, pulumi.DependsOn(clusterDeps)); err != nil {
+		return nil, err
+	}
+
+	out.RuleName = rule.Name
+	out.RuleArn = rule.Arn
+	out.InvokeRoleArn = invokeRole.Arn
+
+	return out, nil
+}
@@ -0,0 +1,142 @@
+package vtech_aws
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// secretValueFrom builds the container `valueFrom` of the secret.
+// Secrets Manager JSON keys use the "<arn>:<json-key>::" syntax
+func secretValueFrom(secret dto.ECSSecret) (pulumi.StringInput, error) {
+	switch {
+	case secret.SecretsManagerArn != nil && secret.SsmParameterArn != nil:
+		return nil, fmt.Errorf("ecs secret %s: SecretsManagerArn and SsmParameterArn are mutually exclusive", secret.Name)
+	case secret.SecretsManagerArn != nil:
+		if secret.JsonKey != "" {
+			return pulumi.Sprintf("%s:%s::", secret.SecretsManagerArn, secret.JsonKey), nil
+		}
+		return secret.SecretsManagerArn, nil
+	case secret.SsmParameterArn != nil:
+		return secret.SsmParameterArn, nil
+	default:
+		return nil, fmt.Errorf("ecs secret %s: one of SecretsManagerArn or SsmParameterArn is required", secret.Name)
+	}
+}
+
+// applyECSSecrets adds the secrets to the `secrets` block of their target container and returns
+// the valueFrom of the secrets already set on the containers, to be granted to the execution role too
+func applyECSSecrets(containers []dto.ContainerDefinition, appName string, secrets []dto.ECSSecret) ([]pulumi.StringInput, error) {
+	var direct []pulumi.StringInput
+	for _, c := range containers {
+		for _, secret := range c.Secrets {
+			if secret.ValueFrom == nil {
+				return nil, fmt.Errorf("ecs container %s: secret %s has no ValueFrom", c.Name, secret.Name)
+			}
+			direct = append(direct, secret.ValueFrom)
+		}
+	}
+
+	for _, secret := range secrets {
+		valueFrom, err := secretValueFrom(secret)
+		if err != nil {
+			return nil, err
+		}
+
+		target := secret.Container
+		if target == "" {
+			target = appName
+		}
+		i := slices.IndexFunc(containers, func(c dto.ContainerDefinition) bool { return c.Name == target })
+		if i < 0 {
+			return nil, fmt.Errorf("ecs secret %s: container %s not found", secret.Name, target)
+		}
+
+		containers[i].Secrets = append(slices.Clip(containers[i].Secrets), dto.ContainerSecret{
+			Name:      secret.Name,
+			ValueFrom: valueFrom,
+		})
+	}
+
+	return direct, nil
+}
+
+// createSecretsPolicy grants the execution role read access to exactly the injected secrets,
+// including the direct container secrets (valueFrom ARNs, see applyECSSecrets)
+func (mod AWSModule) createSecretsPolicy(ctx *pulumi.Context, baseName string, execRole *iam.Role, secrets []dto.ECSSecret, direct []pulumi.StringInput) error {
+	if len(secrets) == 0 && len(direct) == 0 {
+		return nil
+	}
+
+	var smArns, ssmArns, kmsArns pulumi.StringArray
+	for _, secret := range secrets {
+		if secret.SecretsManagerArn != nil {
+			smArns = append(smArns, secret.SecretsManagerArn)
+		}
+		if secret.SsmParameterArn != nil {
+			ssmArns = append(ssmArns, secret.SsmParameterArn)
+		}
+		if secret.KmsKeyArn != nil {
+			kmsArns = append(kmsArns, secret.KmsKeyArn)
+		}
+	}
+
+	var specs []policy.StatementSpec
+	if len(smArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.SECRETS_MANAGER_READ}, ResourceArns: smArns})
+	}
+	if len(ssmArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.SSM_PARAMETERS_READ}, ResourceArns: ssmArns})
+	}
+	if len(kmsArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.KMS_DECRYPT}, ResourceArns: kmsArns})
+	}
+
+	statements := mod.Policies.Build(specs...)
+	// Secrets diretti: il servizio si conosce solo dall'ARN risolto
+	for _, valueFrom := range direct {
+		resource := valueFrom.ToStringOutput().ApplyT(secretResourceArn).(pulumi.StringOutput)
+		statements = append(statements, mod.Policies.ArnStatement(resource, secretGroup))
+	}
+
+	doc := iam.GetPolicyDocumentOutput(ctx, iam.GetPolicyDocumentOutputArgs{
+		Statements: statements,
+	})
+
+	_, err := iam.NewRolePolicy(ctx, baseName+"-exec-secrets", &iam.RolePolicyArgs{
+		Name:   pulumi.String(baseName + "-secrets-policy"),
+		Role:   execRole.Name,
+		Policy: doc.Json(),
+	})
+	return err
+}
+
+// secretResourceArn returns the IAM resource of a container secret valueFrom, dropping the
+// "<arn>:<json-key>:<version-stage>:<version-id>" suffix of the Secrets Manager references
+func secretResourceArn(valueFrom string) (string, error) {
+	parts := strings.Split(valueFrom, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return "", fmt.Errorf("ecs secret: valueFrom %q must be a Secrets Manager or SSM parameter ARN", valueFrom)
+	}
+	if parts[2] == "secretsmanager" && len(parts) > 7 {
+		return strings.Join(parts[:7], ":"), nil
+	}
+	return valueFrom, nil
+}
+
+// secretGroup returns the read permission for the service of the secret ARN
+func secretGroup(arn string) (policy.PolicyGroup, error) {
+	switch strings.SplitN(arn, ":", 4)[2] {
+	case "secretsmanager":
+		return policy.SECRETS_MANAGER_READ, nil
+	case "ssm":
+		return policy.SSM_PARAMETERS_READ, nil
+	default:
+		return "", fmt.Errorf("ecs secret: unsupported valueFrom %q, want a Secrets Manager or SSM parameter ARN", arn)
+	}
+}
@@ -0,0 +1,109 @@
+package vtech_aws
+
+import (
+	"testing"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	testSecretArn    = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:db-AbCdEf"
+	testParameterArn = "arn:aws:ssm:eu-west-1:123456789012:parameter/app/token"
+)
+
+func TestSecretResourceArn(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueFrom string
+		want      string
+		wantErr   bool
+	}{
+		{name: "secret", valueFrom: testSecretArn, want: testSecretArn},
+		{name: "secret json key", valueFrom: testSecretArn + ":password::", want: testSecretArn},
+		{name: "secret version", valueFrom: testSecretArn + ":password:AWSCURRENT:", want: testSecretArn},
+		{name: "parameter", valueFrom: testParameterArn, want: testParameterArn},
+		{name: "parameter name", valueFrom: "/app/token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretResourceArn(tt.valueFrom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("secretResourceArn(%q) error = %v, wantErr %v", tt.valueFrom, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("secretResourceArn(%q) = %q, want %q", tt.valueFrom, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		arn     string
+		want    policy.PolicyGroup
+		wantErr bool
+	}{
+		{name: "secrets manager", arn: testSecretArn, want: policy.SECRETS_MANAGER_READ},
+		{name: "ssm", arn: testParameterArn, want: policy.SSM_PARAMETERS_READ},
+		{name: "unsupported", arn: "arn:aws:s3:::bucket/key", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretGroup(tt.arn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("secretGroup(%q) error = %v, wantErr %v", tt.arn, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("secretGroup(%q) = %s, want %s", tt.arn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyECSSecrets(t *testing.T) {
+	direct := pulumi.String(testParameterArn)
+	containers := []dto.ContainerDefinition{
+		{Name: "app"},
+		{Name: "worker", Secrets: []dto.ContainerSecret{{Name: "TOKEN", ValueFrom: direct}}},
+	}
+	secrets := []dto.ECSSecret{
+		{Name: "DB_PASSWORD", SecretsManagerArn: pulumi.String(testSecretArn), JsonKey: "password"},
+		{Name: "API_KEY", SsmParameterArn: pulumi.String(testParameterArn), Container: "worker"},
+	}
+
+	got, err := applyECSSecrets(containers, "app", secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Solo i secrets già presenti sui container sono "diretti"
+	if len(got) != 1 || got[0] != direct {
+		t.Errorf("direct secrets = %v, want [%v]", got, direct)
+	}
+	if n := len(containers[0].Secrets); n != 1 || containers[0].Secrets[0].Name != "DB_PASSWORD" {
+		t.Errorf("app secrets = %+v", containers[0].Secrets)
+	}
+	if n := len(containers[1].Secrets); n != 2 || containers[1].Secrets[1].Name != "API_KEY" {
+		t.Errorf("worker secrets = %+v", containers[1].Secrets)
+	}
+
+	errorCases := []struct {
+		name       string
+		containers []dto.ContainerDefinition
+		secrets    []dto.ECSSecret
+	}{
+		{name: "unknown container", containers: []dto.ContainerDefinition{{Name: "app"}}, secrets: []dto.ECSSecret{{Name: "X", SsmParameterArn: direct, Container: "missing"}}},
+		{name: "no source", containers: []dto.ContainerDefinition{{Name: "app"}}, secrets: []dto.ECSSecret{{Name: "X"}}},
+		{name: "both sources", containers: []dto.ContainerDefinition{{Name: "app"}}, secrets: []dto.ECSSecret{{Name: "X", SsmParameterArn: direct, SecretsManagerArn: direct}}},
+		{name: "direct without value", containers: []dto.ContainerDefinition{{Name: "app", Secrets: []dto.ContainerSecret{{Name: "X"}}}}},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := applyECSSecrets(tt.containers, "app", tt.secrets); err == nil {
+				t.Error("applyECSSecrets() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package vtech_aws
+
+import (
+	"fmt"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createLambdaAlias points the alias to the version published by this deploy or, with a
+// CanaryWeight, keeps it on FunctionVersion and routes the weight to the new version
+func (mod AWSModule) createLambdaAlias(fnName string, fn *lambda.Function, in dto.LambdaAliasArgs) (*lambda.Alias, error) {
+	if in.Name == "" {
+		return nil, fmt.Errorf("lambda alias %s: Name is required", fnName)
+	}
+	if in.CanaryWeight < 0 || in.CanaryWeight >= 1 {
+		return nil, fmt.Errorf("lambda alias %s: CanaryWeight must be in [0, 1)", fnName)
+	}
+	if in.CanaryWeight > 0 && in.FunctionVersion == "" {
+		return nil, fmt.Errorf("lambda alias %s: CanaryWeight requires the stable FunctionVersion", fnName)
+	}
+
+	args := &lambda.AliasArgs{
+		Name:            pulumi.String(in.Name),
+		FunctionName:    fn.Name,
+		FunctionVersion: fn.Version,
+	}
+	if in.Description != "" {
+		args.Description = pulumi.String(in.Description)
+	}
+	if in.FunctionVersion != "" {
+		args.FunctionVersion = pulumi.String(in.FunctionVersion)
+	}
+	if in.CanaryWeight > 0 {
+		// La chiave è la versione appena pubblicata: deve essere diversa da quella stabile
+		args.RoutingConfig = &lambda.AliasRoutingConfigArgs{
+			AdditionalVersionWeights: fn.Version.ApplyT(func(v string) (map[string]float64, error) {
+				if v == in.FunctionVersion {
+					return nil, fmt.Errorf("lambda alias %s: canary version %s is the stable FunctionVersion, publish a new version or drop CanaryWeight", fnName, v)
+				}
+				return map[string]float64{v: in.CanaryWeight}, nil
+			}).(pulumi.Float64MapOutput),
+		}
+	}
+
+	alias, err := lambda.NewAlias(mod.Ctx, fmt.Sprintf("%s-alias-%s", fnName, in.Name), args)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.ProvisionedConcurrency > 0 {
+		if _, err := lambda.NewProvisionedConcurrencyConfig(mod.Ctx, fmt.Sprintf("%s-alias-%s-pc", fnName, in.Name), &lambda.ProvisionedConcurrencyConfigArgs{
+			FunctionName:                    fn.Name,
+			Qualifier:                       alias.Name,
+			ProvisionedConcurrentExecutions: pulumi.Int(in.ProvisionedConcurrency),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return alias, nil
+}
@@ -0,0 +1,84 @@
+package vtech_aws
+
+import (
+	"fmt"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func destinationPolicyGroup(dest dto.LambdaDestination) (policy.PolicyGroup, error) {
+	switch dest.Type {
+	case dto.LambdaDestinationSQS:
+		return policy.SQS_SEND, nil
+	case dto.LambdaDestinationSNS:
+		return policy.SNS_PUBLISH, nil
+	case dto.LambdaDestinationEventBridge:
+		return policy.EVENTBRIDGE_PUT_EVENTS, nil
+	case dto.LambdaDestinationLambda:
+		return policy.LAMBDA_INVOKE, nil
+	default:
+		return "", fmt.Errorf("lambda destination: unsupported type %q", dest.Type)
+	}
+}
+
+// asyncInvokeStatements returns the role statements needed to deliver to the destinations
+func asyncInvokeStatements(in *dto.LambdaAsyncInvokeArgs) ([]policy.StatementSpec, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	var specs []policy.StatementSpec
+	for _, dest := range []*dto.LambdaDestination{in.OnSuccess, in.OnFailure} {
+		if dest == nil {
+			continue
+		}
+		if dest.Arn == nil {
+			return nil, fmt.Errorf("lambda destination %s: Arn is required", dest.Type)
+		}
+		group, err := destinationPolicyGroup(*dest)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, policy.StatementSpec{
+			Groups:       []policy.PolicyGroup{group},
+			ResourceArns: pulumi.StringArray{dest.Arn},
+		})
+	}
+
+	return specs, nil
+}
+
+// createAsyncInvokeConfig creates the lambda.FunctionEventInvokeConfig.
+// deps must include the role policy granting access to the destinations
+func (mod AWSModule) createAsyncInvokeConfig(fnName string, fn *lambda.Function, qualifier pulumi.StringPtrInput, in *dto.LambdaAsyncInvokeArgs, deps []pulumi.Resource) error {
+	if in == nil {
+		return nil
+	}
+
+	args := &lambda.FunctionEventInvokeConfigArgs{
+		FunctionName:             fn.Name,
+		Qualifier:                qualifier,
+		MaximumRetryAttempts:     pulumi.IntPtrFromPtr(in.MaximumRetryAttempts),
+		MaximumEventAgeInSeconds: pulumi.IntPtrFromPtr(in.MaximumEventAgeInSeconds),
+	}
+	if in.OnSuccess != nil || in.OnFailure != nil {
+		destCfg := &lambda.FunctionEventInvokeConfigDestinationConfigArgs{}
+		if in.OnSuccess != nil {
+			destCfg.OnSuccess = &lambda.FunctionEventInvokeConfigDestinationConfigOnSuccessArgs{
+				Destination: in.OnSuccess.Arn,
+			}
+		}
+		if in.OnFailure != nil {
+			destCfg.OnFailure = &lambda.FunctionEventInvokeConfigDestinationConfigOnFailureArgs{
+				Destination: in.OnFailure.Arn,
+			}
+		}
+		args.DestinationConfig = destCfg
+	}
+
+	_, err := lambda.NewFunctionEventInvokeConfig(mod.Ctx, fmt.Sprintf("%s-async-invoke", fnName), args, pulumi.DependsOn(deps))
+	return err
+}
@@ -0,0 +1,138 @@
+package vtech_aws
+
+import (
+	"fmt"
+	"strings"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/opt"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// eventSourceStatements returns the role statements needed by Lambda to poll the event sources
+// and to send the failed batches to their destinations
+func (mod AWSModule) eventSourceStatements(sources []dto.LambdaEventSource) (iam.GetPolicyDocumentStatementArray, error) {
+	var sqsArns, ddbArns, kinesisArns pulumi.StringArray
+	var onFailure iam.GetPolicyDocumentStatementArray
+	for _, src := range sources {
+		if src.EventSourceArn == nil {
+			return nil, fmt.Errorf("lambda event source %s: EventSourceArn is required", src.Name)
+		}
+
+		switch src.Type {
+		case dto.EventSourceSQS:
+			sqsArns = append(sqsArns, src.EventSourceArn)
+		case dto.EventSourceDynamoDB:
+			ddbArns = append(ddbArns, src.EventSourceArn)
+		case dto.EventSourceKinesis:
+			kinesisArns = append(kinesisArns, src.EventSourceArn)
+		default:
+			return nil, fmt.Errorf("lambda event source %s: unsupported type %q", src.Name, src.Type)
+		}
+
+		if src.OnFailureDestinationArn != nil {
+			if src.Type == dto.EventSourceSQS {
+				return nil, fmt.Errorf("lambda event source %s: OnFailureDestinationArn is only supported for streams, use the queue redrive policy", src.Name)
+			}
+			// Coda o topic: permesso scelto dal servizio dell'ARN
+			onFailure = append(onFailure, mod.Policies.ArnStatement(src.OnFailureDestinationArn, onFailureGroup))
+		}
+	}
+
+	var specs []policy.StatementSpec
+	var listGroups []policy.PolicyGroup
+	if len(sqsArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.SQS_MANAGE}, ResourceArns: sqsArns})
+	}
+	if len(ddbArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.DYNAMODB_STREAM_READ}, ResourceArns: ddbArns})
+		listGroups = append(listGroups, policy.DYNAMODB_LIST_STREAMS)
+	}
+	if len(kinesisArns) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: []policy.PolicyGroup{policy.KINESIS_STREAM_READ}, ResourceArns: kinesisArns})
+		listGroups = append(listGroups, policy.KINESIS_LIST_STREAMS)
+	}
+	// ListStreams non supporta ARN specifici: statement dedicato su "*"
+	if len(listGroups) > 0 {
+		specs = append(specs, policy.StatementSpec{Groups: listGroups, Resources: policy.AllResources()})
+	}
+
+	return append(mod.Policies.Build(specs...), onFailure...), nil
+}
+
+// onFailureGroup returns the send permission for the service of the on-failure destination ARN
+func onFailureGroup(arn string) (policy.PolicyGroup, error) {
+	parts := strings.SplitN(arn, ":", 4)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("lambda event source: invalid on-failure destination %q", arn)
+	}
+	switch parts[2] {
+	case "sqs":
+		return policy.SQS_SEND, nil
+	case "sns":
+		return policy.SNS_PUBLISH, nil
+	default:
+		return "", fmt.Errorf("lambda event source: unsupported on-failure destination %q, want an SQS queue or SNS topic", arn)
+	}
+}
+
+// createEventSourceMappings creates a lambda.EventSourceMapping for each event source on functionArn
+// (the alias ARN when there is one).
+// deps must include the role policy, otherwise AWS rejects the mapping for missing permissions
+func (mod AWSModule) createEventSourceMappings(fnName string, functionArn pulumi.StringInput, sources []dto.LambdaEventSource, deps []pulumi.Resource) error {
+	for i, src := range sources {
+		name := src.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+
+		args := &lambda.EventSourceMappingArgs{
+			FunctionName:   functionArn,
+			EventSourceArn: src.EventSourceArn,
+			Enabled:        pulumi.Bool(opt.Coalesce(src.Enabled, true)),
+			Tags:           mod.DefaultTags,
+		}
+		if src.BatchSize > 0 {
+			args.BatchSize = pulumi.Int(src.BatchSize)
+		}
+		if src.MaximumBatchingWindowInSeconds > 0 {
+			args.MaximumBatchingWindowInSeconds = pulumi.Int(src.MaximumBatchingWindowInSeconds)
+		}
+		if src.ReportBatchItemFailures {
+			args.FunctionResponseTypes = pulumi.StringArray{pulumi.String("ReportBatchItemFailures")}
+		}
+
+		// Stream: posizione iniziale, retry e destinazione on-failure
+		if src.Type != dto.EventSourceSQS {
+			args.StartingPosition = pulumi.String(orDefault(src.StartingPosition, "LATEST"))
+			if src.BisectBatchOnFunctionError {
+				args.BisectBatchOnFunctionError = pulumi.Bool(true)
+			}
+			if src.MaximumRetryAttempts != nil {
+				args.MaximumRetryAttempts = pulumi.Int(*src.MaximumRetryAttempts)
+			}
+			if src.MaximumRecordAgeInSeconds != nil {
+				args.MaximumRecordAgeInSeconds = pulumi.Int(*src.MaximumRecordAgeInSeconds)
+			}
+			if src.ParallelizationFactor > 0 {
+				args.ParallelizationFactor = pulumi.Int(src.ParallelizationFactor)
+			}
+			if src.OnFailureDestinationArn != nil {
+				args.DestinationConfig = &lambda.EventSourceMappingDestinationConfigArgs{
+					OnFailure: &lambda.EventSourceMappingDestinationConfigOnFailureArgs{
+						DestinationArn: src.OnFailureDestinationArn,
+					},
+				}
+			}
+		}
+
+		if _, err := lambda.NewEventSourceMapping(mod.Ctx, fmt.Sprintf("%s-esm-%s", fnName, name), args, pulumi.DependsOn(deps)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
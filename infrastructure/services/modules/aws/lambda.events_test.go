@@ -0,0 +1,32 @@
+package vtech_aws
+
+import (
+	"testing"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+)
+
+func TestOnFailureGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		arn     string
+		want    policy.PolicyGroup
+		wantErr bool
+	}{
+		{name: "sqs", arn: "arn:aws:sqs:eu-west-1:123456789012:failures", want: policy.SQS_SEND},
+		{name: "sns", arn: "arn:aws:sns:eu-west-1:123456789012:failures", want: policy.SNS_PUBLISH},
+		{name: "unsupported service", arn: "arn:aws:s3:::failures", wantErr: true},
+		{name: "not an arn", arn: "failures", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := onFailureGroup(tt.arn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("onFailureGroup(%q) error = %v, wantErr %v", tt.arn, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("onFailureGroup(%q) = %s, want %s", tt.arn, got, tt.want)
+			}
+		})
+	}
+}
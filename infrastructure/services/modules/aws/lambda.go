@@ -5,16 +5,41 @@ import (
 	"fmt"
 
 	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	"github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/cors"
 	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	mappers "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/mappers/aws"
 	lambda_services "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/lambda"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/cloudwatch"
-	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ec2"
 	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
+// CreateLambda creates the function with its role, log group and triggers
 func (mod AWSModule) CreateLambda(args *dto.ServiceLambdaArgs) (*lambda.Function, error) {
+	out, err := mod.CreateLambdaService(args)
+	if err != nil {
+		return nil, err
+	}
+	return out.Function, nil
+}
+
+// CreateLambdaService is CreateLambda returning also the published version and the alias
+func (mod AWSModule) CreateLambdaService(args *dto.ServiceLambdaArgs) (*dto.ServiceLambdaOutput, error) {
 	args.LambdaArgs.Tags = mod.DefaultTags
+	if args.Cors != nil {
+		if err := args.Cors.Validate(); err != nil {
+			return nil, err
+		}
+		encoded, err := args.Cors.Encode()
+		if err != nil {
+			return nil, err
+		}
+		args.LambdaArgs.Environments = withEnvironmentVariable(args.LambdaArgs.Environments, cors.ENV_VAR, encoded)
+	}
+	if args.Alias != nil {
+		args.LambdaArgs.Publish = pulumi.Bool(true)
+	}
 
 	var statements = mod.Policies.Build(
 		policy.StatementSpec{
@@ -30,39 +55,80 @@ func (mod AWSModule) CreateLambda(args *dto.ServiceLambdaArgs) (*lambda.Function
 	for _, v := range args.LambdaArgs.Statements {
 		statements = append(statements, v)
 	}
-	// Policy document
-	getDumperPolicyDoc := iam.GetPolicyDocumentOutput(mod.Ctx, iam.GetPolicyDocumentOutputArgs{
-		Statements: statements,
-	})
 
-	// IAM Role
-	getDumperRole, err := iam.NewRole(mod.Ctx, fmt.Sprintf("%s-role", args.LambdaArgs.Name), &iam.RoleArgs{
-		Name:             pulumi.String(fmt.Sprintf("%s-role", args.LambdaArgs.Name)),
-		AssumeRolePolicy: pulumi.String(policy.IAM_LAMBDA_ASSUME_ROLE),
-	})
+	// Permessi di polling dei trigger (SQS / stream)
+	sourceStatements, err := mod.eventSourceStatements(args.EventSources)
 	if err != nil {
 		return nil, err
 	}
+	statements = append(statements, sourceStatements...)
 
-	// IAM Role Policy
-	_, err = iam.NewRolePolicy(mod.Ctx, fmt.Sprintf("%s-role", args.LambdaArgs.Name), &iam.RolePolicyArgs{
-		Name:   pulumi.String(fmt.Sprintf("%s-policy", args.LambdaArgs.Name)),
-		Role:   getDumperRole.ID(),
-		Policy: getDumperPolicyDoc.Json(),
+	// Permessi verso le destinazioni dell'invocazione asincrona
+	destinationSpecs, err := asyncInvokeStatements(args.AsyncInvoke)
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, mod.Policies.Build(destinationSpecs...)...)
+
+	// IAM Role (+ policy inline e managed), oppure ruolo esistente
+	roleArn, roleDeps, err := mod.createLambdaRole(args.LambdaArgs, statements)
+	if err != nil {
+		return nil, err
+	}
+
+	// VPC con security group dedicato
+	var lambdaSg *ec2.SecurityGroup
+	if args.Vpc != nil {
+		if args.LambdaArgs.VpcConfig != nil {
+			return nil, errors.New("lambda vpc: Vpc and LambdaArgs.VpcConfig are mutually exclusive")
+		}
+		args.LambdaArgs.VpcConfig, lambdaSg, err = mod.createLambdaVpc(args.LambdaArgs.Name, *args.Vpc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Log group associato alla Lambda, creato prima della funzione per evitare quello auto-generato
+	retention := 30
+	if args.LogRetentionDays > 0 {
+		retention = args.LogRetentionDays
+	}
+	logGroup, err := cloudwatch.NewLogGroup(mod.Ctx, fmt.Sprintf("%s-log-group", args.LambdaArgs.Name), &cloudwatch.LogGroupArgs{
+		Name:            pulumi.String(mappers.LambdaLogGroupName(args.LambdaArgs.Name)),
+		RetentionInDays: pulumi.Int(retention),
+		KmsKeyId:        args.LogKmsKeyArn,
+		Tags:            mod.DefaultTags,
 	})
 	if err != nil {
 		return nil, err
 	}
+	fnDeps := pulumi.DependsOn(append([]pulumi.Resource{logGroup}, roleDeps...))
 
 	var fn *lambda.Function
 	switch args.LambdaType {
 	case dto.LambdaTypeEFS:
-		fn, err = lambda_services.CreateLambdaEFS(mod.Ctx, args.LambdaArgs, getDumperRole.Arn)
+		fn, err = lambda_services.CreateLambdaEFS(mod.Ctx, args.LambdaArgs, roleArn, fnDeps)
 	case dto.LambdaTypeS3:
 		fn, err = lambda_services.CreateLambdaS3(mod.Ctx, dto.LambdaS3Input{
 			LambdaArgs:   args.LambdaArgs,
 			LambdaS3Args: *args.LambdaS3Args,
-		}, getDumperRole.Arn)
+		}, roleArn, fnDeps)
+	case dto.LambdaTypeImage:
+		if args.LambdaImageArgs == nil {
+			return nil, errors.New("missing image args for image lambda")
+		}
+		fn, err = lambda_services.CreateLambdaImage(mod.Ctx, dto.LambdaImageInput{
+			LambdaArgs:      args.LambdaArgs,
+			LambdaImageArgs: *args.LambdaImageArgs,
+		}, roleArn, fnDeps)
+	case dto.LambdaTypeGo:
+		if args.LambdaGoArgs == nil {
+			return nil, errors.New("missing go args for go lambda")
+		}
+		fn, err = lambda_services.CreateLambdaGo(mod.Ctx, dto.LambdaGoInput{
+			LambdaArgs:   args.LambdaArgs,
+			LambdaGoArgs: *args.LambdaGoArgs,
+		}, roleArn, fnDeps)
 	default:
 		fn, err = nil, errors.New("unsupported lambda type")
 	}
@@ -70,15 +136,72 @@ func (mod AWSModule) CreateLambda(args *dto.ServiceLambdaArgs) (*lambda.Function
 		return nil, err
 	}
 
-	// Log group associato alla Lambda
-	_, err = cloudwatch.NewLogGroup(mod.Ctx, fmt.Sprintf("%s-log-group", args.LambdaArgs.Name), &cloudwatch.LogGroupArgs{
-		Name:            pulumi.String(fmt.Sprintf("/aws/lambda/%s", args.LambdaArgs.Name)),
-		RetentionInDays: pulumi.Int(30),
-		Tags:            mod.DefaultTags,
-	})
-	if err != nil {
+	out := &dto.ServiceLambdaOutput{
+		Function:  fn,
+		Version:   fn.Version,
+		Arn:       fn.Arn,
+		InvokeArn: fn.InvokeArn,
+	}
+
+	if lambdaSg != nil {
+		out.SecurityGroupId = lambdaSg.ID().ToStringOutput()
+	}
+
+	// Alias (+ canary e provisioned concurrency)
+	if args.Alias != nil {
+		alias, err := mod.createLambdaAlias(args.LambdaArgs.Name, fn, *args.Alias)
+		if err != nil {
+			return nil, err
+		}
+		out.Alias = alias
+		out.Arn = alias.Arn
+		out.InvokeArn = alias.InvokeArn
+		out.Qualifier = alias.Name
+	}
+
+	// Event source mappings, sull'alias se presente (canary incluso)
+	if err := mod.createEventSourceMappings(args.LambdaArgs.Name, out.Arn, args.EventSources, roleDeps); err != nil {
 		return nil, err
 	}
 
-	return fn, nil
+	// Function URL
+	if args.FunctionUrl != nil {
+		url, err := mod.createFunctionUrl(args.LambdaArgs.Name, fn, out.Qualifier, *args.FunctionUrl)
+		if err != nil {
+			return nil, err
+		}
+		out.FunctionUrl = url.FunctionUrl
+	}
+
+	// Invocazione asincrona (retry e destinazioni)
+	if err := mod.createAsyncInvokeConfig(args.LambdaArgs.Name, fn, out.Qualifier, args.AsyncInvoke, roleDeps); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// withEnvironmentVariable adds the variable to the function environment, keeping the existing ones
+func withEnvironmentVariable(env *lambda.FunctionEnvironmentArgs, key string, value string) *lambda.FunctionEnvironmentArgs {
+	if env == nil || env.Variables == nil {
+		return &lambda.FunctionEnvironmentArgs{Variables: pulumi.StringMap{key: pulumi.String(value)}}
+	}
+	if vars, ok := env.Variables.(pulumi.StringMap); ok {
+		merged := make(pulumi.StringMap, len(vars)+1)
+		for k, v := range vars {
+			merged[k] = v
+		}
+		merged[key] = pulumi.String(value)
+		return &lambda.FunctionEnvironmentArgs{Variables: merged}
+	}
+	return &lambda.FunctionEnvironmentArgs{
+		Variables: env.Variables.ToStringMapOutput().ApplyT(func(vars map[string]string) map[string]string {
+			merged := make(map[string]string, len(vars)+1)
+			for k, v := range vars {
+				merged[k] = v
+			}
+			merged[key] = value
+			return merged
+		}).(pulumi.StringMapOutput),
+	}
 }
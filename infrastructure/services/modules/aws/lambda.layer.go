@@ -0,0 +1,87 @@
+package vtech_aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	lambda_core "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/services/aws/lambda/core"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CreateLayer builds the layer archive and publishes a new lambda.LayerVersion when its content changes
+func (mod AWSModule) CreateLayer(args dto.LayerArgs) (*dto.LayerOutput, error) {
+	if args.Name == "" {
+		return nil, errors.New("lambda layer: Name is required")
+	}
+
+	build, err := lambda_core.BuildSource(mod.Ctx, lambda_core.BuildOptions{
+		BuildCommand:   args.BuildCommand,
+		WorkingDir:     args.WorkingDir,
+		OutputPath:     args.OutputPath,
+		SourceDir:      args.SourceDir,
+		IgnorePatterns: args.BuildIgnore,
+		CacheDir:       filepath.Join(args.WorkingDir, lambda_core.DEFAULT_BUILD_CACHE_DIR, "layer-"+args.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	layerArgs := &lambda.LayerVersionArgs{
+		LayerName:               pulumi.String(args.Name),
+		Code:                    build.Archive,
+		SourceCodeHash:          pulumi.String(build.SourceCodeHash),
+		CompatibleRuntimes:      pulumi.ToStringArray(args.CompatibleRuntimes),
+		CompatibleArchitectures: pulumi.ToStringArray(args.CompatibleArchitectures),
+	}
+	if args.Description != "" {
+		layerArgs.Description = pulumi.String(args.Description)
+	}
+	if args.LicenseInfo != "" {
+		layerArgs.LicenseInfo = pulumi.String(args.LicenseInfo)
+	}
+
+	layer, err := lambda.NewLayerVersion(mod.Ctx, fmt.Sprintf("%s-layer", args.Name), layerArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Condivisione con altri account / organizzazione
+	versionNumber := layer.Version.ApplyT(strconv.Atoi).(pulumi.IntOutput)
+	share := func(id string, principal string, orgID string) error {
+		permArgs := &lambda.LayerVersionPermissionArgs{
+			LayerName:     layer.LayerName,
+			VersionNumber: versionNumber,
+			StatementId:   pulumi.String(fmt.Sprintf("share-%s", id)),
+			Action:        pulumi.String("lambda:GetLayerVersion"),
+			Principal:     pulumi.String(principal),
+		}
+		if orgID != "" {
+			permArgs.OrganizationId = pulumi.String(orgID)
+		}
+		_, err := lambda.NewLayerVersionPermission(mod.Ctx, fmt.Sprintf("%s-layer-share-%s", args.Name, id), permArgs)
+		return err
+	}
+	for _, account := range args.ShareWithAccounts {
+		id := account
+		if account == "*" {
+			id = "all"
+		}
+		if err := share(id, account, ""); err != nil {
+			return nil, err
+		}
+	}
+	if args.ShareWithOrganizationId != "" {
+		if err := share(args.ShareWithOrganizationId, "*", args.ShareWithOrganizationId); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.LayerOutput{
+		LayerVersion: layer,
+		Arn:          layer.Arn,
+	}, nil
+}
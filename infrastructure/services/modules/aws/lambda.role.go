@@ -0,0 +1,65 @@
+package vtech_aws
+
+import (
+	"fmt"
+
+	policy "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/config/aws"
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createLambdaRole creates the function role with the inline statements and the managed policies.
+// With an existing RoleArn nothing is created: the role must already grant the needed permissions.
+// The returned resources must be awaited by the function and its triggers
+func (mod AWSModule) createLambdaRole(args dto.LambdaArgs, statements iam.GetPolicyDocumentStatementArray) (pulumi.StringInput, []pulumi.Resource, error) {
+	if args.RoleArn != nil {
+		mod.Ctx.Log.Info(fmt.Sprintf("[Lambda] %s uses an existing role: inline statements, managed policies and boundary are not applied", args.Name), nil)
+		return args.RoleArn, nil, nil
+	}
+
+	roleArgs := &iam.RoleArgs{
+		Name:             pulumi.String(fmt.Sprintf("%s-role", args.Name)),
+		AssumeRolePolicy: pulumi.String(policy.IAM_LAMBDA_ASSUME_ROLE),
+		Tags:             mod.DefaultTags,
+	}
+	if args.RolePath != "" {
+		roleArgs.Path = pulumi.String(args.RolePath)
+	}
+	if args.PermissionsBoundaryArn != "" {
+		roleArgs.PermissionsBoundary = pulumi.String(args.PermissionsBoundaryArn)
+	}
+
+	role, err := iam.NewRole(mod.Ctx, fmt.Sprintf("%s-role", args.Name), roleArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Policy inline
+	doc := iam.GetPolicyDocumentOutput(mod.Ctx, iam.GetPolicyDocumentOutputArgs{
+		Statements: statements,
+	})
+	rolePolicy, err := iam.NewRolePolicy(mod.Ctx, fmt.Sprintf("%s-role", args.Name), &iam.RolePolicyArgs{
+		Name:   pulumi.String(fmt.Sprintf("%s-policy", args.Name)),
+		Role:   role.ID(),
+		Policy: doc.Json(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	deps := []pulumi.Resource{rolePolicy}
+
+	// Managed policies
+	for i, arn := range args.ManagedPolicyArns {
+		attachment, err := iam.NewRolePolicyAttachment(mod.Ctx, fmt.Sprintf("%s-role-managed-%d", args.Name, i), &iam.RolePolicyAttachmentArgs{
+			Role:      role.Name,
+			PolicyArn: pulumi.String(arn),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		deps = append(deps, attachment)
+	}
+
+	return role.Arn, deps, nil
+}
@@ -0,0 +1,72 @@
+package vtech_aws
+
+import (
+	"fmt"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createFunctionUrl exposes the function (or its alias) through a lambda.FunctionUrl.
+// With AuthType NONE the public InvokeFunctionUrl and InvokeFunction permissions are added as well
+func (mod AWSModule) createFunctionUrl(fnName string, fn *lambda.Function, qualifier pulumi.StringPtrInput, in dto.LambdaFunctionUrlArgs) (*lambda.FunctionUrl, error) {
+	authType := orDefault(in.AuthType, "AWS_IAM")
+	if authType != "AWS_IAM" && authType != "NONE" {
+		return nil, fmt.Errorf("lambda function url %s: unsupported auth type %q", fnName, authType)
+	}
+
+	args := &lambda.FunctionUrlArgs{
+		FunctionName:      fn.Name,
+		Qualifier:         qualifier,
+		AuthorizationType: pulumi.String(authType),
+		InvokeMode:        pulumi.String(orDefault(in.InvokeMode, "BUFFERED")),
+	}
+	if in.Cors != nil {
+		// Stessa policy delle API Gateway, ridotta a quanto accettano le Function URL
+		policy, err := in.Cors.FunctionUrl()
+		if err != nil {
+			return nil, fmt.Errorf("lambda function url %s: %w", fnName, err)
+		}
+		cors := &lambda.FunctionUrlCorsArgs{
+			AllowOrigins:     pulumi.ToStringArray(policy.AllowOrigins),
+			AllowMethods:     pulumi.ToStringArray(policy.AllowMethods),
+			AllowHeaders:     pulumi.ToStringArray(policy.AllowHeaders),
+			ExposeHeaders:    pulumi.ToStringArray(policy.ExposeHeaders),
+			AllowCredentials: pulumi.Bool(policy.AllowCredentials),
+		}
+		if policy.MaxAge > 0 {
+			cors.MaxAge = pulumi.Int(policy.MaxAge)
+		}
+		args.Cors = cors
+	}
+
+	url, err := lambda.NewFunctionUrl(mod.Ctx, fmt.Sprintf("%s-url", fnName), args)
+	if err != nil {
+		return nil, err
+	}
+
+	if authType == "NONE" {
+		if _, err := lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-url-public-permission", fnName), &lambda.PermissionArgs{
+			Action:              pulumi.String("lambda:InvokeFunctionUrl"),
+			Function:            fn.Name,
+			Qualifier:           qualifier,
+			Principal:           pulumi.String("*"),
+			FunctionUrlAuthType: pulumi.String("NONE"),
+		}); err != nil {
+			return nil, err
+		}
+		// Le nuove Function URL richiedono anche lambda:InvokeFunction, limitato alle chiamate via URL
+		if _, err := lambda.NewPermission(mod.Ctx, fmt.Sprintf("%s-url-public-invoke-permission", fnName), &lambda.PermissionArgs{
+			Action:                pulumi.String("lambda:InvokeFunction"),
+			Function:              fn.Name,
+			Qualifier:             qualifier,
+			Principal:             pulumi.String("*"),
+			InvokedViaFunctionUrl: pulumi.Bool(true),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return url, nil
+}
@@ -0,0 +1,110 @@
+package vtech_aws
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	dto "github.com/VincenzoTumbiolo/Infra-PlumiCommons-Package/infrastructure/dto/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/lambda"
+	"github.com/pulumi/pulumi-aws/sdk/v7/go/aws/vpc"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// createLambdaVpc creates the function security group with only the given egress rules
+// (plus the matching ingress on the destination security groups) and resolves the subnets
+func (mod AWSModule) createLambdaVpc(fnName string, in dto.LambdaVpcArgs) (*lambda.FunctionVpcConfigArgs, *ec2.SecurityGroup, error) {
+	if in.VpcId == nil {
+		return nil, nil, fmt.Errorf("lambda vpc %s: VpcId is required", fnName)
+	}
+
+	// Subnet
+	var subnetIds pulumi.StringArrayInput = in.SubnetIds
+	if len(in.SubnetIds) == 0 {
+		if len(in.SubnetTags) == 0 {
+			return nil, nil, fmt.Errorf("lambda vpc %s: one of SubnetIds or SubnetTags is required", fnName)
+		}
+		filters := ec2.GetSubnetsFilterArray{
+			&ec2.GetSubnetsFilterArgs{
+				Name:   pulumi.String("vpc-id"),
+				Values: pulumi.StringArray{in.VpcId},
+			},
+		}
+		for _, k := range slices.Sorted(maps.Keys(in.SubnetTags)) {
+			filters = append(filters, &ec2.GetSubnetsFilterArgs{
+				Name:   pulumi.String("tag:" + k),
+				Values: pulumi.StringArray{pulumi.String(in.SubnetTags[k])},
+			})
+		}
+		subnetIds = ec2.GetSubnetsOutput(mod.Ctx, ec2.GetSubnetsOutputArgs{Filters: filters}).Ids()
+	}
+
+	// Security group senza regole inline: solo l'egress esplicito
+	sg, err := ec2.NewSecurityGroup(mod.Ctx, fmt.Sprintf("%s-sg", fnName), &ec2.SecurityGroupArgs{
+		Name:        pulumi.String(fmt.Sprintf("%s-lambda-sg", fnName)),
+		Description: pulumi.String(fmt.Sprintf("Lambda %s", fnName)),
+		VpcId:       in.VpcId,
+		Tags:        mod.DefaultTags,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	egress := slices.Clone(in.Egress)
+	if in.AllowHttpsEgress {
+		egress = append(egress, dto.LambdaVpcEgress{Name: "https", CidrBlock: "0.0.0.0/0", Port: 443})
+	}
+	for i, e := range egress {
+		name := e.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		if (e.SecurityGroupId == nil) == (e.CidrBlock == "") {
+			return nil, nil, fmt.Errorf("lambda vpc %s: egress %s requires exactly one of SecurityGroupId or CidrBlock", fnName, name)
+		}
+		if e.Port == 0 {
+			return nil, nil, fmt.Errorf("lambda vpc %s: egress %s requires Port", fnName, name)
+		}
+		port := e.Port
+		protocol := orDefault(e.Protocol, "tcp")
+
+		egressArgs := &vpc.SecurityGroupEgressRuleArgs{
+			SecurityGroupId: sg.ID(),
+			IpProtocol:      pulumi.String(protocol),
+			FromPort:        pulumi.Int(port),
+			ToPort:          pulumi.Int(port),
+			Description:     pulumi.String(fmt.Sprintf("%s to %s", fnName, name)),
+			Tags:            mod.DefaultTags,
+		}
+		if e.SecurityGroupId != nil {
+			egressArgs.ReferencedSecurityGroupId = e.SecurityGroupId
+		} else {
+			egressArgs.CidrIpv4 = pulumi.String(e.CidrBlock)
+		}
+		if _, err := vpc.NewSecurityGroupEgressRule(mod.Ctx, fmt.Sprintf("%s-sg-egress-%s", fnName, name), egressArgs); err != nil {
+			return nil, nil, err
+		}
+
+		// Ingress corrispondente sul SG di destinazione (es. cluster RDS).
+		// Regola separata: il SG di destinazione non deve avere regole inline, vedi dto.LambdaVpcEgress
+		if e.SecurityGroupId != nil {
+			if _, err := vpc.NewSecurityGroupIngressRule(mod.Ctx, fmt.Sprintf("%s-sg-ingress-%s", fnName, name), &vpc.SecurityGroupIngressRuleArgs{
+				SecurityGroupId:           e.SecurityGroupId,
+				ReferencedSecurityGroupId: sg.ID(),
+				IpProtocol:                pulumi.String(protocol),
+				FromPort:                  pulumi.Int(port),
+				ToPort:                    pulumi.Int(port),
+				Description:               pulumi.String(fmt.Sprintf("From Lambda %s", fnName)),
+				Tags:                      mod.DefaultTags,
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return &lambda.FunctionVpcConfigArgs{
+		SubnetIds:        subnetIds,
+		SecurityGroupIds: pulumi.StringArray{sg.ID()},
+	}, sg, nil
+}